@@ -0,0 +1,318 @@
+// accounts/accounts.go
+//
+// Package accounts creates the configured superusers and editors
+// and keeps track of the accounts it created.
+package accounts
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/mails"
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"pocketbase/config"
+)
+
+// DefaultPassword is the shared password used when neither ADMIN_PASSWORD
+// nor ADMIN_INVITE is set, it is only meant for local development
+const DefaultPassword = "valiantlynx_admin_2025"
+
+// RoleSuperuser creates a _superusers record, the other roles
+// are stored in the role field of the users auth collection
+const RoleSuperuser = "superuser"
+
+// roles lists the accepted account roles
+var roles = []string{RoleSuperuser, "admin", "manager", "editor", "user"}
+
+// seededParam is the _params key holding the accounts handled by Seed
+const seededParam = "seeded_accounts"
+
+// Account describes a single account to create
+type Account struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+	Name  string `json:"name"`
+
+	// Password overrides the shared password, leave empty to use ADMIN_PASSWORD or ADMIN_INVITE
+	Password string `json:"password"`
+}
+
+// errNotAuthCollection is returned for accounts of a collection that isn't an auth collection
+var errNotAuthCollection = errors.New("not an auth collection")
+
+// seededAccount is a configured account Seed has handled. Every account is
+// only seeded once, so accounts deleted afterwards are not created again.
+type seededAccount struct {
+	Email      string `json:"email"`
+	Collection string `json:"collection,omitempty"`
+	Id         string `json:"id,omitempty"`
+
+	// Existing is set when the email was already taken, Remove leaves those alone
+	Existing bool `json:"existing,omitempty"`
+}
+
+// List returns the configured accounts: ADMIN_EMAIL, ADMIN_EMAILS and the seed file entries
+func List(cfg *config.Config) ([]Account, error) {
+	var list []Account
+	for _, email := range append([]string{cfg.Admin.Email}, cfg.Admin.Emails...) {
+		if email != "" {
+			list = append(list, Account{Email: email, Role: RoleSuperuser})
+		}
+	}
+
+	if cfg.Admin.SeedFile != "" {
+		seeded, err := LoadFile(cfg.Admin.SeedFile)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, seeded...)
+	}
+
+	return list, nil
+}
+
+// Seed creates the configured accounts that weren't seeded before.
+//
+// Every account gets its own password, the shared ADMIN_PASSWORD or, with ADMIN_INVITE
+// (or for additional accounts without a password outside of development), a random
+// password and a password reset email. Handled accounts are recorded in the
+// seeded_accounts param: they are not recreated after being deleted and Remove
+// only deletes the accounts that were created.
+func Seed(app core.App, cfg *config.Config) error {
+	list, err := List(cfg)
+	if err != nil {
+		return err
+	}
+
+	seeded, err := loadSeeded(app)
+	if err != nil {
+		return err
+	}
+
+	done := map[string]bool{}
+	for i, account := range seeded {
+		// entries written before emails were recorded
+		if account.Email == "" {
+			if record, err := app.FindRecordById(account.Collection, account.Id); err == nil {
+				seeded[i].Email = record.Email()
+			}
+		}
+		done[strings.ToLower(seeded[i].Email)] = true
+	}
+
+	var names []string
+	changed := false
+	for _, account := range list {
+		if done[strings.ToLower(account.Email)] {
+			continue
+		}
+
+		record, invite, err := createAccount(app, cfg, account)
+		if errors.Is(err, errNotAuthCollection) {
+			// The initial migrations create the users collection as a base collection and
+			// convert it later, its accounts are seeded on the next start
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create account %s: %w", account.Email, err)
+		}
+		done[strings.ToLower(account.Email)] = true
+		changed = true
+
+		if record == nil {
+			seeded = append(seeded, seededAccount{Email: account.Email, Existing: true})
+			continue
+		}
+
+		seeded = append(seeded, seededAccount{Email: account.Email, Collection: record.Collection().Name, Id: record.Id})
+		names = append(names, account.Email+" ("+account.Role+")")
+
+		if invite {
+			if err := mails.SendRecordPasswordReset(app, record); err != nil {
+				log.Printf("Warning: failed to send the password email to %s, use \"forgot password\" instead: %v", account.Email, err)
+			}
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if err := saveSeeded(app, seeded); err != nil {
+		return err
+	}
+
+	if len(names) > 0 {
+		log.Printf("Accounts created: %s", strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Remove deletes the accounts created by Seed, other accounts are left untouched
+func Remove(app core.App) error {
+	seeded, err := loadSeeded(app)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range seeded {
+		if account.Existing {
+			continue
+		}
+		record, err := app.FindRecordById(account.Collection, account.Id)
+		if err != nil {
+			// already deleted
+			continue
+		}
+		if err := app.Delete(record); err != nil {
+			return fmt.Errorf("failed to delete account %s: %w", record.Email(), err)
+		}
+	}
+
+	param := &core.Param{}
+	if err := app.ModelQuery(param).Model(seededParam, param); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	return app.Delete(param)
+}
+
+// createAccount creates the account unless its email is already taken and reports
+// whether a password email should be sent. It returns a nil record for existing accounts.
+func createAccount(app core.App, cfg *config.Config, account Account) (*core.Record, bool, error) {
+	collectionName := core.CollectionNameSuperusers
+	if account.Role != RoleSuperuser {
+		collectionName = cfg.AuthCollection
+	}
+
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if !collection.IsAuth() {
+		return nil, false, fmt.Errorf("%s is %w", collectionName, errNotAuthCollection)
+	}
+
+	if _, err := app.FindAuthRecordByEmail(collection, account.Email); err == nil {
+		return nil, false, nil
+	}
+
+	password, invite := account.Password, false
+	switch {
+	case password != "":
+	case cfg.Admin.Invite:
+		invite = true
+	case cfg.Admin.Password != "":
+		password = cfg.Admin.Password
+	case cfg.IsDev():
+		password = DefaultPassword
+	case account.Email == cfg.Admin.Email:
+		// the default is public, the primary superuser needs a real password outside of development
+		return nil, false, errors.New("ADMIN_PASSWORD, ADMIN_PASSWORD_FILE or ADMIN_INVITE must be set outside of development")
+	default:
+		invite = true
+	}
+	if invite {
+		password = security.RandomString(40)
+	}
+
+	record := core.NewRecord(collection)
+	record.SetEmail(account.Email)
+	record.SetPassword(password)
+	record.SetVerified(true)
+
+	if collectionName != core.CollectionNameSuperusers {
+		setIfExists(record, "name", account.Name)
+		setIfExists(record, "role", account.Role)
+		setIfExists(record, "username", Username(strings.Split(account.Email, "@")[0]))
+	} else {
+		record.SetEmailVisibility(true)
+	}
+
+	if err := app.Save(record); err != nil {
+		return nil, false, err
+	}
+
+	return record, invite, nil
+}
+
+// setIfExists sets the field when the collection has it and value is not empty
+func setIfExists(record *core.Record, field string, value string) {
+	if value != "" && record.Collection().Fields.GetByName(field) != nil {
+		record.Set(field, value)
+	}
+}
+
+var nonUsernameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// Username derives a username from an email local part or a display name
+func Username(source string) string {
+	return strings.Trim(nonUsernameChars.ReplaceAllString(strings.ToLower(source), "_"), "_")
+}
+
+// validate checks the email and role of a seed file entry
+func (a Account) validate() error {
+	if _, err := mail.ParseAddress(a.Email); err != nil {
+		return fmt.Errorf("invalid email %q: %w", a.Email, err)
+	}
+
+	for _, role := range roles {
+		if a.Role == role {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid role %q for %s, expected one of %s", a.Role, a.Email, strings.Join(roles, ", "))
+}
+
+func loadSeeded(app core.App) ([]seededAccount, error) {
+	param := &core.Param{}
+	if err := app.ModelQuery(param).Model(seededParam, param); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to load seeded accounts: %w", err)
+	}
+
+	var seeded []seededAccount
+	if err := json.Unmarshal(param.Value, &seeded); err != nil {
+		return nil, fmt.Errorf("failed to load seeded accounts: %w", err)
+	}
+
+	return seeded, nil
+}
+
+func saveSeeded(app core.App, seeded []seededAccount) error {
+	value, err := json.Marshal(seeded)
+	if err != nil {
+		return err
+	}
+
+	param := &core.Param{}
+	if err := app.ModelQuery(param).Model(seededParam, param); err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		param.Id = seededParam
+		param.MarkAsNew()
+	}
+	param.Value = value
+
+	if err := app.Save(param); err != nil {
+		return fmt.Errorf("failed to save seeded accounts: %w", err)
+	}
+
+	return nil
+}
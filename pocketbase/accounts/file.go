@@ -0,0 +1,47 @@
+// accounts/file.go
+package accounts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFile reads a YAML or JSON list of accounts, e.g.:
+//
+//	[
+//	  {"email": "editor@valiantlynx.com", "role": "editor", "name": "Editor"},
+//	  {"email": "ops@valiantlynx.com", "role": "superuser"}
+//	]
+func LoadFile(path string) ([]Account, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	// YAML is a superset of JSON, decode into plain values and reuse the json tags
+	var raw []map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+
+	var list []Account
+	if err := json.Unmarshal(encoded, &list); err != nil {
+		return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+	}
+
+	for _, account := range list {
+		if err := account.validate(); err != nil {
+			return nil, fmt.Errorf("invalid seed file %s: %w", path, err)
+		}
+	}
+
+	return list, nil
+}
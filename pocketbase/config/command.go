@@ -0,0 +1,172 @@
+// config/command.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Init registers the config flags and the "config" command on rootCmd
+// and loads the configuration, flags overriding the file and the environment.
+//
+// Example usage:
+//
+//	cfg, err := config.Init(app.RootCmd)
+func Init(rootCmd *cobra.Command) (*Config, error) {
+	if rootCmd == nil {
+		return nil, fmt.Errorf("missing root command")
+	}
+
+	flags := rootCmd.PersistentFlags()
+	configFile := flags.String("config", "", "config file (default CONFIG_FILE or ./"+DefaultFile+" if it exists)")
+	flagValues := registerFlags(flags, reflect.TypeOf(Config{}))
+
+	rootCmd.AddCommand(newConfigCommand())
+
+	// The flags are needed before the app is bootstrapped, so they are parsed the
+	// way PocketBase eagerly parses its own (--dir, --dev, ...): by the root command,
+	// which skips the unknown flags of subcommands. Errors (and --help) are
+	// reported by cobra when the command is executed.
+	_ = rootCmd.ParseFlags(os.Args[1:])
+
+	cfg, err := Load(*configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyFlags(flags, reflect.ValueOf(cfg).Elem(), flagValues); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	currentMu.Lock()
+	current = cfg
+	currentMu.Unlock()
+
+	return cfg, nil
+}
+
+// registerFlags defines a string flag for every field with a flag tag,
+// keyed by the field index path
+func registerFlags(flags *pflag.FlagSet, t reflect.Type) map[string]*string {
+	values := map[string]*string{}
+	walkFields(t, nil, func(field reflect.StructField, index []int) {
+		name := field.Tag.Get("flag")
+		if name == "" {
+			return
+		}
+		values[name] = flags.String(name, "", field.Tag.Get("usage")+" ("+field.Tag.Get("env")+")")
+	})
+	return values
+}
+
+// applyFlags sets the fields of the flags that were passed on the command line
+func applyFlags(flags *pflag.FlagSet, v reflect.Value, values map[string]*string) error {
+	var err error
+	walkFields(v.Type(), nil, func(field reflect.StructField, index []int) {
+		name := field.Tag.Get("flag")
+		if name == "" || err != nil || !flags.Changed(name) {
+			return
+		}
+		if setErr := setFromString(v.FieldByIndex(index), *values[name]); setErr != nil {
+			err = fmt.Errorf("invalid --%s: %w", name, setErr)
+		}
+	})
+	return err
+}
+
+// walkFields calls fn for every non-struct field of t, nested structs included
+func walkFields(t reflect.Type, index []int, fn func(field reflect.StructField, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			walkFields(field.Type, fieldIndex, fn)
+			continue
+		}
+		fn(field, fieldIndex)
+	}
+}
+
+func newConfigCommand() *cobra.Command {
+	command := &cobra.Command{
+		Use:   "config",
+		Short: "Configuration helpers",
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:          "print",
+		Short:        "Prints the effective configuration with secrets redacted",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := Get()
+			if err != nil {
+				return err
+			}
+			out, err := cfg.Redacted()
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+			return nil
+		},
+	})
+
+	return command
+}
+
+// Redacted returns the configuration as YAML with every set secret replaced by "***"
+func (c *Config) Redacted() (string, error) {
+	copied := *c
+	redact(reflect.ValueOf(&copied).Elem())
+
+	// Round trip through JSON so the output uses the same keys as config.yaml
+	encoded, err := json.Marshal(copied)
+	if err != nil {
+		return "", err
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(encoded, &raw); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(raw); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// redact replaces the set secret fields of v, pointers are replaced rather than written through
+func redact(v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		value := v.Field(i)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct:
+			redact(value)
+		case field.Tag.Get("secret") != "true":
+		case value.Kind() == reflect.String && value.String() != "":
+			value.SetString("***")
+		case value.Kind() == reflect.Pointer && !value.IsNil():
+			masked := "***"
+			value.Set(reflect.ValueOf(&masked))
+		}
+	}
+}
@@ -0,0 +1,316 @@
+// config/config.go
+package config
+
+import (
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Supported APP_ENV values
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Config is the typed application configuration.
+//
+// Values are loaded in order from the defaults, an optional config.yaml
+// (keys as in the json tags), the environment (env tags, with <NAME>_FILE support)
+// and the command line flags (flag tags), each step overriding the previous one.
+// Fields tagged secret are redacted by "config print".
+//
+// Pointer fields are optional app settings, nil means "leave the current setting as is".
+type Config struct {
+	// Env switches between development (automigrate, no password guard) and production
+	Env string `json:"env" env:"APP_ENV" flag:"env" usage:"application environment (development or production)"`
+
+	Admin AdminConfig `json:"admin"`
+
+	// Mailer selects how emails are delivered, "smtp" (default) or "file"
+	Mailer string `json:"mailer" env:"MAILER" flag:"mailer" usage:"mail delivery (smtp or file)"`
+
+	// AuthCollection is the auth collection the OAuth2 providers are configured on
+	AuthCollection string `json:"authCollection" env:"AUTH_COLLECTION"`
+
+	// ShutdownTimeout is the deadline in seconds for draining background jobs on shutdown
+	ShutdownTimeout int `json:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT" flag:"shutdown-timeout" usage:"seconds to drain background jobs on shutdown"`
+
+	// PublicDir serves the static site from this directory instead of the embedded
+	// files or ./pb_public, e.g. the SvelteKit build output during development
+	PublicDir string `json:"publicDir" env:"PB_PUBLIC_DIR" flag:"public-dir" usage:"directory of the static site"`
+
+	App          AppConfig          `json:"app"`
+	SMTP         SMTPConfig         `json:"smtp"`
+	Logs         LogsConfig         `json:"logs"`
+	RateLimits   RateLimitsConfig   `json:"rateLimits"`
+	TrustedProxy TrustedProxyConfig `json:"trustedProxy"`
+	Batch        BatchConfig        `json:"batch"`
+	OAuth2       OAuth2Config       `json:"oauth2"`
+	Security     SecurityConfig     `json:"security"`
+	CORS         CORSConfig         `json:"cors"`
+}
+
+// AdminConfig holds the accounts created on first start
+type AdminConfig struct {
+	Email    string `json:"email" env:"ADMIN_EMAIL"`
+	Password string `json:"password" env:"ADMIN_PASSWORD" secret:"true"`
+
+	// Emails are additional superusers, ADMIN_EMAILS is comma separated
+	Emails []string `json:"emails" env:"ADMIN_EMAILS"`
+
+	// SeedFile is a YAML or JSON list of superusers and editors to create
+	SeedFile string `json:"seedFile" env:"ADMIN_SEED_FILE"`
+
+	// Invite sends every new account a password reset email
+	// instead of using the shared password
+	Invite bool `json:"invite" env:"ADMIN_INVITE"`
+}
+
+// AppConfig holds the application meta settings
+type AppConfig struct {
+	Name          *string `json:"name" env:"APP_NAME"`
+	URL           *string `json:"url" env:"APP_URL" flag:"app-url" usage:"public application URL"`
+	SenderName    *string `json:"senderName" env:"SENDER_NAME"`
+	SenderAddress *string `json:"senderAddress" env:"SENDER_ADDRESS"`
+}
+
+// SMTPConfig holds the SMTP settings, setting Host enables SMTP unless Enabled is set
+type SMTPConfig struct {
+	Enabled    *bool   `json:"enabled" env:"SMTP_ENABLED"`
+	Host       *string `json:"host" env:"SMTP_HOST"`
+	Port       *int    `json:"port" env:"SMTP_PORT"`
+	Username   *string `json:"username" env:"SMTP_USERNAME"`
+	Password   *string `json:"password" env:"SMTP_PASSWORD" secret:"true"`
+	AuthMethod *string `json:"authMethod" env:"SMTP_AUTH_METHOD"`
+	TLS        *bool   `json:"tls" env:"SMTP_TLS"`
+	LocalName  *string `json:"localName" env:"SMTP_LOCAL_NAME"`
+}
+
+// LogsConfig holds the log retention settings
+type LogsConfig struct {
+	MaxDays   *int  `json:"maxDays" env:"LOGS_MAX_DAYS"`
+	MinLevel  *int  `json:"minLevel" env:"LOGS_MIN_LEVEL"`
+	LogIP     *bool `json:"logIP" env:"LOGS_LOG_IP"`
+	LogAuthId *bool `json:"logAuthId" env:"LOGS_LOG_AUTH_ID"`
+}
+
+// RateLimitsConfig holds the rate limit settings, RATE_LIMITS_RULES is a JSON array
+type RateLimitsConfig struct {
+	Enabled *bool                `json:"enabled" env:"RATE_LIMITS_ENABLED"`
+	Rules   []core.RateLimitRule `json:"rules" env:"RATE_LIMITS_RULES"`
+}
+
+// Shorthands of TRUSTED_PROXY_CIDRS
+const (
+	ProxyCloudflare = "cloudflare"
+	ProxyPrivate    = "private"
+)
+
+// TrustedProxyConfig holds the client IP header settings,
+// TRUSTED_PROXY_HEADERS and TRUSTED_PROXY_CIDRS are comma separated
+type TrustedProxyConfig struct {
+	Headers       []string `json:"headers" env:"TRUSTED_PROXY_HEADERS"`
+	UseLeftmostIP *bool    `json:"useLeftmostIP" env:"TRUSTED_PROXY_USE_LEFTMOST_IP"`
+
+	// CIDRs are the proxies whose headers are trusted, "cloudflare" and "private"
+	// add Cloudflare's and the private and loopback ranges. Empty trusts the
+	// headers of every client, as PocketBase does.
+	CIDRs []string `json:"cidrs" env:"TRUSTED_PROXY_CIDRS"`
+
+	// CountryHeader carries the client's country code, set by Cloudflare's IP geolocation
+	CountryHeader string `json:"countryHeader" env:"TRUSTED_PROXY_COUNTRY_HEADER"`
+}
+
+// BatchConfig holds the batch API limits
+type BatchConfig struct {
+	Enabled     *bool  `json:"enabled" env:"BATCH_ENABLED"`
+	MaxRequests *int   `json:"maxRequests" env:"BATCH_MAX_REQUESTS"`
+	Timeout     *int64 `json:"timeout" env:"BATCH_TIMEOUT"`
+	MaxBodySize *int64 `json:"maxBodySize" env:"BATCH_MAX_BODY_SIZE"`
+}
+
+// CSP modes
+const (
+	CSPEnforce    = "enforce"
+	CSPReportOnly = "report-only"
+	CSPOff        = "off"
+)
+
+// SecurityConfig holds the security headers of every response.
+// CSP_SOURCES and FRAME_ANCESTORS are comma separated.
+type SecurityConfig struct {
+	// CSP is "enforce", "report-only" (violations are only reported) or "off"
+	CSP string `json:"csp" env:"CSP_MODE"`
+
+	// CSPSources are additional hosts pages may load scripts, styles, images,
+	// frames and connections from, e.g. "https://cdn.example.com"
+	CSPSources []string `json:"cspSources" env:"CSP_SOURCES"`
+
+	// FrameAncestors may embed the site in frames
+	FrameAncestors []string `json:"frameAncestors" env:"FRAME_ANCESTORS"`
+
+	// HSTSMaxAge is the Strict-Transport-Security max-age in seconds for HTTPS, 0 disables it
+	HSTSMaxAge int `json:"hstsMaxAge" env:"HSTS_MAX_AGE"`
+
+	// HSTSIncludeSubdomains extends HSTS to every subdomain, only enable it
+	// when all of them are served over HTTPS
+	HSTSIncludeSubdomains bool `json:"hstsIncludeSubdomains" env:"HSTS_INCLUDE_SUBDOMAINS"`
+
+	// ReportRetention is the number of days CSP reports are kept, 0 keeps them
+	ReportRetention int `json:"reportRetention" env:"CSP_REPORT_RETENTION"`
+
+	ReferrerPolicy string `json:"referrerPolicy" env:"REFERRER_POLICY"`
+}
+
+// CORSConfig holds the origins browsers may call the API from, CORS_ALLOWED_ORIGINS is comma separated
+type CORSConfig struct {
+	// AllowedOrigins are e.g. "https://www.valiantlynx.com", "https://*.valiantlynx.com"
+	// for the subdomains or "*" for any origin. Empty allows the origin of APP_URL
+	// and in development the SvelteKit dev and preview servers.
+	AllowedOrigins []string `json:"allowedOrigins" env:"CORS_ALLOWED_ORIGINS" flag:"cors-origins" usage:"origins allowed to call the API (comma separated)"`
+
+	// AllowCredentials lets the allowed origins send cookies and HTTP authentication
+	AllowCredentials bool `json:"allowCredentials" env:"CORS_ALLOW_CREDENTIALS"`
+
+	// MaxAge is how long browsers may cache a preflight response, in seconds
+	MaxAge int `json:"maxAge" env:"CORS_MAX_AGE"`
+}
+
+// OAuth2Config holds the OAuth2 provider credentials
+type OAuth2Config struct {
+	Google   OAuth2Provider `json:"google" envPrefix:"GOOGLE_"`
+	Github   OAuth2Provider `json:"github" envPrefix:"GITHUB_"`
+	Discord  OAuth2Provider `json:"discord" envPrefix:"DISCORD_"`
+	Facebook OAuth2Provider `json:"facebook" envPrefix:"FACEBOOK_"`
+
+	// OIDC is a generic OpenID Connect provider, used for SamletNorge
+	OIDC OAuth2Provider `json:"oidc" envPrefix:"OIDC_"`
+}
+
+// OAuth2Provider holds the credentials of a single provider.
+// The endpoints are only needed for the generic OIDC provider.
+type OAuth2Provider struct {
+	ClientId     string `json:"clientId" env:"CLIENT_ID"`
+	ClientSecret string `json:"clientSecret" env:"CLIENT_SECRET" secret:"true"`
+	AuthURL      string `json:"authURL" env:"AUTH_URL"`
+	TokenURL     string `json:"tokenURL" env:"TOKEN_URL"`
+	UserInfoURL  string `json:"userInfoURL" env:"USER_INFO_URL"`
+	DisplayName  string `json:"displayName" env:"DISPLAY_NAME"`
+}
+
+// Enabled reports whether the provider has credentials
+func (p OAuth2Provider) Enabled() bool {
+	return p.ClientId != "" && p.ClientSecret != ""
+}
+
+// defaults returns the configuration used when nothing else is set
+func defaults() *Config {
+	return &Config{
+		Env:             EnvProduction,
+		AuthCollection:  "users_valiantlynx",
+		ShutdownTimeout: 10,
+		Admin: AdminConfig{
+			Email: "admin@valiantlynx.com",
+		},
+		OAuth2: OAuth2Config{
+			OIDC: OAuth2Provider{DisplayName: "SamletNorge"},
+		},
+		Security: SecurityConfig{
+			CSP:             CSPReportOnly,
+			FrameAncestors:  []string{"'self'"},
+			HSTSMaxAge:      15552000,
+			ReportRetention: 30,
+			ReferrerPolicy:  "strict-origin-when-cross-origin",
+		},
+		CORS: CORSConfig{
+			MaxAge: 3600,
+		},
+		TrustedProxy: TrustedProxyConfig{
+			CountryHeader: "CF-IPCountry",
+		},
+	}
+}
+
+// IsDev reports whether the app runs in development mode
+func (c *Config) IsDev() bool {
+	return c.Env == EnvDevelopment
+}
+
+// Validate checks the loaded values
+func (c *Config) Validate() error {
+	if c.Env != EnvDevelopment && c.Env != EnvProduction {
+		return fmt.Errorf("APP_ENV must be %q or %q, got %q", EnvDevelopment, EnvProduction, c.Env)
+	}
+
+	if c.Mailer != "" && c.Mailer != "smtp" && c.Mailer != "file" {
+		return fmt.Errorf("MAILER must be \"smtp\" or \"file\", got %q", c.Mailer)
+	}
+
+	if c.ShutdownTimeout < 1 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must be at least 1 second, got %d", c.ShutdownTimeout)
+	}
+
+	if c.Security.CSP != CSPEnforce && c.Security.CSP != CSPReportOnly && c.Security.CSP != CSPOff {
+		return fmt.Errorf("CSP_MODE must be %q, %q or %q, got %q", CSPEnforce, CSPReportOnly, CSPOff, c.Security.CSP)
+	}
+
+	if c.Security.HSTSMaxAge < 0 {
+		return fmt.Errorf("HSTS_MAX_AGE must not be negative, got %d", c.Security.HSTSMaxAge)
+	}
+
+	if c.Security.ReportRetention < 0 {
+		return fmt.Errorf("CSP_REPORT_RETENTION must not be negative, got %d", c.Security.ReportRetention)
+	}
+
+	if c.CORS.AllowCredentials && slices.Contains(c.CORS.AllowedOrigins, "*") {
+		return fmt.Errorf("CORS_ALLOW_CREDENTIALS can't be combined with CORS_ALLOWED_ORIGINS=*, list the origins")
+	}
+
+	for _, cidr := range c.TrustedProxy.CIDRs {
+		if cidr == ProxyCloudflare || cidr == ProxyPrivate {
+			continue
+		}
+		if _, err := netip.ParsePrefix(cidr); err != nil {
+			if _, err := netip.ParseAddr(cidr); err != nil {
+				return fmt.Errorf("TRUSTED_PROXY_CIDRS contains an invalid range %q, use CIDRs, IPs, %q or %q", cidr, ProxyCloudflare, ProxyPrivate)
+			}
+		}
+	}
+
+	if c.Admin.Email != "" {
+		if _, err := mail.ParseAddress(c.Admin.Email); err != nil {
+			return fmt.Errorf("ADMIN_EMAIL is not a valid email address: %w", err)
+		}
+	}
+
+	for _, email := range c.Admin.Emails {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return fmt.Errorf("ADMIN_EMAILS contains an invalid email address %q: %w", email, err)
+		}
+	}
+
+	if c.App.URL != nil {
+		if u, err := url.Parse(*c.App.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("APP_URL must be an absolute URL, got %q", *c.App.URL)
+		}
+	}
+
+	if c.SMTP.Port != nil && (*c.SMTP.Port < 1 || *c.SMTP.Port > 65535) {
+		return fmt.Errorf("SMTP_PORT must be between 1 and 65535, got %d", *c.SMTP.Port)
+	}
+
+	if c.Logs.MaxDays != nil && *c.Logs.MaxDays < 0 {
+		return fmt.Errorf("LOGS_MAX_DAYS must not be negative, got %d", *c.Logs.MaxDays)
+	}
+
+	if oidc := c.OAuth2.OIDC; oidc.Enabled() && (oidc.AuthURL == "" || oidc.TokenURL == "" || oidc.UserInfoURL == "") {
+		return fmt.Errorf("OIDC_AUTH_URL, OIDC_TOKEN_URL and OIDC_USER_INFO_URL are required when the OIDC provider is enabled")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// config/env.go
+//
+// Package config loads the typed application configuration from config.yaml,
+// the environment and the command line flags.
+package config
+
+import (
+	"log"
+	"os"
+	"strings"
+)
+
+// Env returns the value of the environment variable key.
+//
+// When key is not set but key+"_FILE" is, the value is read from that file instead
+// (with surrounding whitespace trimmed), which allows passing secrets as Docker secrets:
+//
+//	ADMIN_PASSWORD_FILE=/run/secrets/admin_password
+func Env(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("Warning: failed to read %s_FILE: %v", key, err)
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
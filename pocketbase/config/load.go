@@ -0,0 +1,181 @@
+// config/load.go
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFile is the config file loaded from the working directory when it exists
+const DefaultFile = "config.yaml"
+
+var (
+	current   *Config
+	currentMu sync.Mutex
+)
+
+// Get returns the loaded configuration.
+// When Init wasn't called (e.g. from a migration run by another binary)
+// the configuration is loaded from config.yaml and the environment only,
+// an invalid configuration is returned as error instead of being cached.
+func Get() (*Config, error) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+
+	if current == nil {
+		cfg, err := Load("")
+		if err != nil {
+			return nil, fmt.Errorf("invalid configuration: %w", err)
+		}
+		current = cfg
+	}
+
+	return current, nil
+}
+
+// Load reads the defaults, the config file and the environment and validates the result.
+// When path is empty CONFIG_FILE or DefaultFile (if it exists) is used.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+
+	if err := cfg.loadFile(path); err != nil {
+		return nil, err
+	}
+
+	if err := loadEnv(reflect.ValueOf(cfg).Elem(), ""); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadFile merges the YAML config file into cfg
+func (cfg *Config) loadFile(path string) error {
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+
+	required := path != ""
+	if path == "" {
+		path = DefaultFile
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !required && errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// YAML is decoded into plain values first so that the json tags
+	// (and the JSON formats of the PocketBase types) are reused for the keys
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// loadEnv sets every field with an env tag whose variable is set
+func loadEnv(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		value := v.Field(i)
+
+		if field.Type.Kind() == reflect.Struct {
+			if err := loadEnv(value, prefix+field.Tag.Get("envPrefix")); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key := field.Tag.Get("env")
+		if key == "" {
+			continue
+		}
+		key = prefix + key
+
+		raw := Env(key)
+		if raw == "" {
+			continue
+		}
+
+		if err := setFromString(value, raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setFromString parses raw into v. Slices of strings are comma separated,
+// other slices are JSON arrays.
+func setFromString(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setFromString(ptr.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.String {
+			list := []string{}
+			for _, item := range strings.Split(raw, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			v.Set(reflect.ValueOf(list))
+			return nil
+		}
+		return json.Unmarshal([]byte(raw), v.Addr().Interface())
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+// config/settings.go
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// settingsField maps a configuration value to an app setting
+type settingsField struct {
+	// key is the environment variable name used in the logs
+	key string
+
+	// secret values are never logged
+	secret bool
+
+	// apply sets the configured value (if any) and returns it when the setting changed
+	apply func(settings *core.Settings, cfg *Config) (bool, any)
+}
+
+// settingsFields lists every configuration value that is applied to the app settings on boot.
+// Keep in sync with the "Environment Variables" section of the README.
+var settingsFields = []settingsField{
+	// Application
+	{key: "APP_NAME", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Meta.AppName, c.App.Name) }},
+	{key: "APP_URL", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Meta.AppURL, c.App.URL) }},
+	{key: "SENDER_NAME", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Meta.SenderName, c.App.SenderName) }},
+	{key: "SENDER_ADDRESS", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setValue(&s.Meta.SenderAddress, c.App.SenderAddress)
+	}},
+
+	// SMTP (setting SMTP_HOST enables SMTP unless SMTP_ENABLED says otherwise)
+	{key: "SMTP_HOST", apply: func(s *core.Settings, c *Config) (bool, any) {
+		if c.SMTP.Host == nil {
+			return false, nil
+		}
+		enabled := true
+		hostChanged, host := setValue(&s.SMTP.Host, c.SMTP.Host)
+		enabledChanged, _ := setValue(&s.SMTP.Enabled, &enabled)
+		return hostChanged || enabledChanged, host
+	}},
+	{key: "SMTP_ENABLED", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.Enabled, c.SMTP.Enabled) }},
+	{key: "SMTP_PORT", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.Port, c.SMTP.Port) }},
+	{key: "SMTP_USERNAME", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.Username, c.SMTP.Username) }},
+	{key: "SMTP_PASSWORD", secret: true, apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.Password, c.SMTP.Password) }},
+	{key: "SMTP_AUTH_METHOD", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.AuthMethod, c.SMTP.AuthMethod) }},
+	{key: "SMTP_TLS", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.TLS, c.SMTP.TLS) }},
+	{key: "SMTP_LOCAL_NAME", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.SMTP.LocalName, c.SMTP.LocalName) }},
+
+	// Logs
+	{key: "LOGS_MAX_DAYS", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Logs.MaxDays, c.Logs.MaxDays) }},
+	{key: "LOGS_MIN_LEVEL", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Logs.MinLevel, c.Logs.MinLevel) }},
+	{key: "LOGS_LOG_IP", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Logs.LogIP, c.Logs.LogIP) }},
+	{key: "LOGS_LOG_AUTH_ID", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Logs.LogAuthId, c.Logs.LogAuthId) }},
+
+	// Rate limits
+	{key: "RATE_LIMITS_ENABLED", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setValue(&s.RateLimits.Enabled, c.RateLimits.Enabled)
+	}},
+	{key: "RATE_LIMITS_RULES", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setSlice(&s.RateLimits.Rules, c.RateLimits.Rules)
+	}},
+
+	// Trusted proxy
+	{key: "TRUSTED_PROXY_HEADERS", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setSlice(&s.TrustedProxy.Headers, c.TrustedProxy.Headers)
+	}},
+	{key: "TRUSTED_PROXY_USE_LEFTMOST_IP", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setValue(&s.TrustedProxy.UseLeftmostIP, c.TrustedProxy.UseLeftmostIP)
+	}},
+
+	// Batch API
+	{key: "BATCH_ENABLED", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Batch.Enabled, c.Batch.Enabled) }},
+	{key: "BATCH_MAX_REQUESTS", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setValue(&s.Batch.MaxRequests, c.Batch.MaxRequests)
+	}},
+	{key: "BATCH_TIMEOUT", apply: func(s *core.Settings, c *Config) (bool, any) { return setValue(&s.Batch.Timeout, c.Batch.Timeout) }},
+	{key: "BATCH_MAX_BODY_SIZE", apply: func(s *core.Settings, c *Config) (bool, any) {
+		return setValue(&s.Batch.MaxBodySize, c.Batch.MaxBodySize)
+	}},
+}
+
+// ApplySettings applies the configured settings to settings and returns
+// a description of every changed value (secrets are redacted)
+func ApplySettings(settings *core.Settings, cfg *Config) []string {
+	var changes []string
+	for _, field := range settingsFields {
+		changed, value := field.apply(settings, cfg)
+		if !changed {
+			continue
+		}
+
+		display := fmt.Sprint(value)
+		if field.secret {
+			display = "***"
+		}
+		changes = append(changes, field.key+"="+display)
+	}
+
+	return changes
+}
+
+// ReconcileSettings applies the configured settings to the app settings
+// and saves them if anything changed, logging every overridden value
+func ReconcileSettings(app core.App, cfg *Config) error {
+	settings := app.Settings()
+
+	changes := ApplySettings(settings, cfg)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := app.Save(settings); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+
+	log.Printf("Settings overridden from configuration: %s", strings.Join(changes, ", "))
+	return nil
+}
+
+// setValue sets an optional value, nil leaves the setting unchanged
+func setValue[T comparable](dst *T, value *T) (bool, any) {
+	if value == nil || *dst == *value {
+		return false, nil
+	}
+	*dst = *value
+	return true, *value
+}
+
+// setSlice sets an optional list, comparing old and new by their JSON representation
+// (which is also returned for logging)
+func setSlice[T any](dst *[]T, value []T) (bool, any) {
+	if value == nil {
+		return false, nil
+	}
+	before, _ := json.Marshal(*dst)
+	after, _ := json.Marshal(value)
+	if string(before) == string(after) {
+		return false, nil
+	}
+	*dst = value
+	return true, string(after)
+}
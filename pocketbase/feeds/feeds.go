@@ -0,0 +1,226 @@
+// feeds/feeds.go
+//
+// Package feeds serves sitemap.xml, robots.txt and the RSS 2.0, Atom and JSON
+// feeds of the published blogs, so they are available when PocketBase serves
+// the static build without the SvelteKit server.
+package feeds
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/sites"
+	"pocketbase/static"
+)
+
+// feedLimit is the number of latest blogs in a feed
+const feedLimit = 50
+
+// publishedFilter matches the blogs that are publicly visible
+const publishedFilter = "published = true"
+
+// formats are the feed encoders keyed by the last path segment
+var formats = map[string]struct {
+	contentType string
+	encode      func(f *feed) ([]byte, error)
+}{
+	"rss.xml":   {"application/rss+xml; charset=utf-8", encodeRSS},
+	"atom.xml":  {"application/atom+xml; charset=utf-8", encodeAtom},
+	"feed.json": {"application/feed+json; charset=utf-8", encodeJSON},
+}
+
+// Register adds the sitemap, robots.txt and feed routes
+func Register(app core.App) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/robots.txt", robots)
+		se.Router.GET("/sitemap.xml", sitemapIndex)
+		se.Router.BindFunc(serveSitemapPage)
+
+		se.Router.GET("/feeds/{format}", allFeed)
+		se.Router.GET("/feeds/tags/{tag}/{format}", tagFeed)
+		se.Router.GET("/feeds/authors/{author}/{format}", authorFeed)
+
+		return se.Next()
+	})
+}
+
+// feed is a list of blogs, independent of the output format
+type feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Updated     time.Time
+	Entries     []entry
+}
+
+// entry is a single blog of a feed
+type entry struct {
+	Id         string
+	Title      string
+	Link       string
+	Summary    string
+	Image      string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+func allFeed(e *core.RequestEvent) error {
+	return serveFeed(e, "", publishedFilter, nil)
+}
+
+func tagFeed(e *core.RequestEvent) error {
+	filter, params := sites.Scope(e, "id = {:tag} || slug = {:tag}", dbx.Params{"tag": e.Request.PathValue("tag")})
+	tag, err := e.App.FindFirstRecordByFilter("tags", filter, params)
+	if err != nil {
+		return e.NotFoundError("Unknown tag.", err)
+	}
+
+	// tags is a JSON array of ids in a text field, filters can only match it with LIKE
+	hasTag := dbx.NewExp(
+		"EXISTS (SELECT 1 FROM json_each(CASE WHEN json_valid([[blogs.tags]]) THEN [[blogs.tags]] ELSE '[]' END) WHERE [[value]] = {:tag})",
+		dbx.Params{"tag": tag.Id},
+	)
+	return serveFeed(e, tag.GetString("name"), publishedFilter, nil, hasTag)
+}
+
+func authorFeed(e *core.RequestEvent) error {
+	blogs, err := e.App.FindCollectionByNameOrId("blogs")
+	if err != nil {
+		return err
+	}
+
+	field, ok := blogs.Fields.GetByName("author").(*core.RelationField)
+	if !ok {
+		return e.NotFoundError("", nil)
+	}
+
+	author, err := e.App.FindFirstRecordByFilter(field.CollectionId, "id = {:author} || username = {:author}", dbx.Params{"author": e.Request.PathValue("author")})
+	if err != nil {
+		return e.NotFoundError("Unknown author.", err)
+	}
+
+	return serveFeed(e, authorName(author), publishedFilter+" && author = {:author}", dbx.Params{"author": author.Id})
+}
+
+// serveFeed writes the latest blogs of the site matching filter and the extra conditions
+// in the requested format, subtitle is appended to the site name in the feed title
+func serveFeed(e *core.RequestEvent, subtitle string, filter string, params dbx.Params, extra ...dbx.Expression) error {
+	format, ok := formats[e.Request.PathValue("format")]
+	if !ok {
+		return e.NotFoundError("Unknown feed format, use rss.xml, atom.xml or feed.json.", nil)
+	}
+
+	site, err := sites.Find(e)
+	if err != nil {
+		return err
+	}
+
+	base := sites.URL(e)
+	f := &feed{
+		Title:   "Blog",
+		Link:    base + "/blogs",
+		FeedURL: base + e.Request.URL.EscapedPath(),
+	}
+	if site != nil {
+		f.Title = site.GetString("site_name")
+		f.Description = site.GetString("site_description")
+		// feeds without entries are as old as the site settings
+		f.Updated = site.GetDateTime("updated").Time()
+	}
+	if subtitle != "" {
+		f.Title += " - " + subtitle
+	}
+
+	filter, params = sites.Scope(e, filter, params)
+	query, err := filterQuery(e.App, "blogs", filter, params)
+	if err != nil {
+		return err
+	}
+
+	var blogs []*core.Record
+	err = query.AndWhere(dbx.And(extra...)).OrderBy("[[blogs.created]] DESC").Limit(feedLimit).All(&blogs)
+	if err != nil {
+		return err
+	}
+	if errs := e.App.ExpandRecords(blogs, []string{"author"}, nil); len(errs) > 0 {
+		e.App.Logger().Warn("Failed to expand the blog authors of a feed", "errors", errs)
+	}
+
+	tags := tagNames(e.App, blogs)
+	for _, blog := range blogs {
+		item := entry{
+			Title:     blog.GetString("title"),
+			Link:      base + "/blogs/" + blog.GetString("slug"),
+			Summary:   blog.GetString("summary"),
+			Image:     sites.AbsoluteURL(base, blog.GetString("image")),
+			Published: blog.GetDateTime("created").Time(),
+			Updated:   blog.GetDateTime("updated").Time(),
+		}
+		item.Id = item.Link
+		if author := blog.ExpandedOne("author"); author != nil {
+			item.Author = authorName(author)
+		}
+		for _, id := range tagIds(blog) {
+			if name, ok := tags[id]; ok {
+				item.Categories = append(item.Categories, name)
+			}
+		}
+
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Entries = append(f.Entries, item)
+	}
+
+	body, err := format.encode(f)
+	if err != nil {
+		return err
+	}
+
+	return static.ServeContent(e, format.contentType, f.Updated, body)
+}
+
+// tagIds returns the tag ids of the blog's tags JSON list
+func tagIds(blog *core.Record) []string {
+	var ids []string
+	if err := json.Unmarshal([]byte(blog.GetString("tags")), &ids); err != nil {
+		return nil
+	}
+	return ids
+}
+
+// tagNames returns the names of all tags of the blogs, keyed by id
+func tagNames(app core.App, blogs []*core.Record) map[string]string {
+	var ids []string
+	for _, blog := range blogs {
+		ids = append(ids, tagIds(blog)...)
+	}
+
+	names := map[string]string{}
+	if len(ids) == 0 {
+		return names
+	}
+
+	tags, err := app.FindRecordsByIds("tags", ids)
+	if err != nil {
+		return names
+	}
+	for _, tag := range tags {
+		names[tag.Id] = tag.GetString("name")
+	}
+	return names
+}
+
+// authorName returns the display name of a user
+func authorName(user *core.Record) string {
+	if name := user.GetString("name"); name != "" {
+		return name
+	}
+	return user.GetString("username")
+}
@@ -0,0 +1,117 @@
+// feeds/feeds_test.go
+package feeds
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+
+	"pocketbase/sites"
+)
+
+func TestTagFeedMatchesExactTagIds(t *testing.T) {
+	scenarios := []tests.ApiScenario{
+		{
+			Name:           "underscores aren't wildcards",
+			Method:         http.MethodGet,
+			URL:            "/feeds/tags/c_/feed.json",
+			ExpectedStatus: http.StatusOK,
+			ExpectedContent: []string{
+				`"title":"Underscore"`,
+				`"title":"Both"`,
+			},
+			NotExpectedContent: []string{
+				`"title":"Lookalike"`,
+				`"title":"Percent"`,
+				`"title":"Draft"`,
+			},
+			TestAppFactory: tagFeedApp,
+		},
+		{
+			Name:            "percent signs aren't wildcards",
+			Method:          http.MethodGet,
+			URL:             "/feeds/tags/c%25/feed.json",
+			ExpectedStatus:  http.StatusOK,
+			ExpectedContent: []string{`"title":"Percent"`},
+			NotExpectedContent: []string{
+				`"title":"Underscore"`,
+				`"title":"Both"`,
+				`"title":"Lookalike"`,
+			},
+			TestAppFactory: tagFeedApp,
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario.Test(t)
+	}
+}
+
+// tagFeedApp creates the blogs of the tag feed tests, some tag ids contain LIKE wildcards
+func tagFeedApp(t testing.TB) *tests.TestApp {
+	app, err := tests.NewTestApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site := core.NewBaseCollection(sites.Collection)
+	site.Fields.Add(&core.TextField{Name: "site_name"})
+	mustSave(t, app, site)
+
+	tags := core.NewBaseCollection("tags")
+	tags.Fields.Add(&core.TextField{Name: "name"}, &core.TextField{Name: "slug"}, &core.TextField{Name: "site"})
+	mustSave(t, app, tags)
+
+	blogs := core.NewBaseCollection("blogs")
+	blogs.Fields.Add(
+		&core.TextField{Name: "title"},
+		&core.TextField{Name: "slug"},
+		&core.TextField{Name: "tags"},
+		&core.TextField{Name: "site"},
+		&core.BoolField{Name: "published"},
+		&core.AutodateField{Name: "created", OnCreate: true},
+		&core.AutodateField{Name: "updated", OnCreate: true, OnUpdate: true},
+	)
+	mustSave(t, app, blogs)
+
+	for id, slug := range map[string]string{"tag_c_": "c_", "tag_cx": "cx", "tag_c%": "c%"} {
+		tag := core.NewRecord(tags)
+		tag.Id = id
+		tag.Set("name", slug)
+		tag.Set("slug", slug)
+		// the ids have characters the default id pattern doesn't allow
+		if err := app.SaveNoValidate(tag); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for title, values := range map[string]struct {
+		tags      string
+		published bool
+	}{
+		"Underscore": {`["tag_c_"]`, true},
+		"Both":       {`["tag_cx", "tag_c_"]`, true},
+		"Lookalike":  {`["tag_cx"]`, true},
+		"Percent":    {`["tag_c%"]`, true},
+		"Draft":      {`["tag_c_"]`, false},
+	} {
+		blog := core.NewRecord(blogs)
+		blog.Set("title", title)
+		blog.Set("slug", title)
+		blog.Set("tags", values.tags)
+		blog.Set("published", values.published)
+		mustSave(t, app, blog)
+	}
+
+	Register(app)
+
+	return app
+}
+
+func mustSave(t testing.TB, app core.App, model core.Model) {
+	if err := app.Save(model); err != nil {
+		t.Fatal(err)
+	}
+}
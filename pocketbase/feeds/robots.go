@@ -0,0 +1,42 @@
+// feeds/robots.go
+package feeds
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/sites"
+	"pocketbase/static"
+)
+
+// defaultRobots are the rules used when the site record doesn't set robots_txt
+const defaultRobots = `User-agent: *
+Allow: /
+Disallow: /api/
+Disallow: /_/`
+
+// robots serves robots.txt with the rules of the site record and the sitemap URL
+func robots(e *core.RequestEvent) error {
+	site, err := sites.Find(e)
+	if err != nil {
+		return err
+	}
+
+	rules := defaultRobots
+	var modified time.Time
+	if site != nil {
+		if custom := strings.TrimSpace(site.GetString("robots_txt")); custom != "" {
+			rules = custom
+		}
+		modified = site.GetDateTime("updated").Time()
+	}
+
+	text := rules + "\n"
+	if !strings.Contains(strings.ToLower(rules), "sitemap:") {
+		text += "\nSitemap: " + sites.URL(e) + "/sitemap.xml\n"
+	}
+
+	return static.ServeContent(e, "text/plain; charset=utf-8", modified, []byte(text))
+}
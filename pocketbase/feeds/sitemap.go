@@ -0,0 +1,323 @@
+// feeds/sitemap.go
+package feeds
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/search"
+
+	"pocketbase/sites"
+	"pocketbase/static"
+)
+
+// sitemapPageSize is the number of URLs per sitemap page, well below the limit of 50000
+const sitemapPageSize = 5000
+
+// staticPages are the routes of the site without a record
+var staticPages = []string{"/", "/blogs", "/blogs/tags", "/projects", "/about", "/contact", "/privacy-policy"}
+
+type sitemapIndexDocument struct {
+	XMLName  xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 sitemapindex"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type urlSetDocument struct {
+	XMLName xml.Name     `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+	Image   string       `xml:"xmlns:image,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string        `xml:"loc"`
+	LastMod string        `xml:"lastmod,omitempty"`
+	Image   *sitemapImage `xml:"image:image,omitempty"`
+}
+
+type sitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+// sitemapEntry is a single URL of the sitemap
+type sitemapEntry struct {
+	path     string
+	image    string
+	modified time.Time
+}
+
+// sitemapSource is a collection whose records are listed in the sitemap
+type sitemapSource struct {
+	collection string
+	filter     string
+	path       func(record *core.Record) string
+	image      string
+}
+
+// sitemapSources follow the static pages, each sorted by the latest update
+var sitemapSources = []sitemapSource{
+	{"blogs", publishedFilter, func(r *core.Record) string { return "/blogs/" + r.GetString("slug") }, "image"},
+	{"tags", "", func(r *core.Record) string { return "/blogs/tags/" + r.Id }, ""},
+	{"projects_valiantlynx", "", func(r *core.Record) string { return "/projects/" + r.Id }, "thumbnail"},
+}
+
+// sitemapPagePattern matches the path of a sitemap page, /sitemap-{n}.xml
+var sitemapPagePattern = regexp.MustCompile(`^/sitemap-([1-9][0-9]*)\.xml$`)
+
+// sitemapCounts returns the number of listed records of every source
+func sitemapCounts(e *core.RequestEvent) ([]int, error) {
+	counts := make([]int, len(sitemapSources))
+	for i, source := range sitemapSources {
+		filter, params := sites.Scope(e, source.filter, nil)
+		count, err := countRecords(e.App, source.collection, filter, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", source.collection, err)
+		}
+		counts[i] = count
+	}
+	return counts, nil
+}
+
+// countRecords returns the number of records of the collection matching filter
+func countRecords(app core.App, collectionName string, filter string, params dbx.Params) (int, error) {
+	query, err := filterQuery(app, collectionName, filter, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = query.Select("count(*)").Row(&count)
+	return count, err
+}
+
+// filterQuery returns the record query of the collection limited to filter,
+// for conditions that can't be written as a filter
+func filterQuery(app core.App, collectionName string, filter string, params dbx.Params) (*dbx.SelectQuery, error) {
+	collection, err := app.FindCachedCollectionByNameOrId(collectionName)
+	if err != nil {
+		return nil, err
+	}
+
+	query := app.RecordQuery(collection)
+
+	resolver := core.NewRecordFieldResolver(app, collection, nil, true)
+	expr, err := search.FilterData(filter).BuildExpr(resolver, params)
+	if err != nil {
+		return nil, err
+	}
+	query.AndWhere(expr)
+
+	if err := resolver.UpdateQuery(query); err != nil {
+		return nil, err
+	}
+
+	return query, nil
+}
+
+// sitemapRange is the part of a source within a sitemap page
+type sitemapRange struct {
+	source sitemapSource
+	offset int
+	limit  int
+}
+
+// pageRanges returns the static pages and the source ranges of the 1-based page
+func pageRanges(counts []int, page int) ([]string, []sitemapRange) {
+	start := (page - 1) * sitemapPageSize
+	end := start + sitemapPageSize
+
+	var paths []string
+	if start < len(staticPages) {
+		paths = staticPages[start:min(end, len(staticPages))]
+	}
+
+	var ranges []sitemapRange
+	first := len(staticPages)
+	for i, source := range sitemapSources {
+		from, to := max(start-first, 0), min(end-first, counts[i])
+		if from < to {
+			ranges = append(ranges, sitemapRange{source: source, offset: from, limit: to - from})
+		}
+		first += counts[i]
+	}
+
+	return paths, ranges
+}
+
+// findRange loads the records of the range, the newest first
+func findRange(e *core.RequestEvent, r sitemapRange) ([]*core.Record, error) {
+	filter, params := sites.Scope(e, r.source.filter, nil)
+	records, err := e.App.FindRecordsByFilter(r.source.collection, filter, "-updated,id", r.limit, r.offset, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", r.source.collection, err)
+	}
+	return records, nil
+}
+
+// sitemapEntries returns the static pages and the published blogs, tags and
+// projects of the site on the 1-based page, only the records of the page are loaded
+func sitemapEntries(e *core.RequestEvent, counts []int, page int) ([]sitemapEntry, error) {
+	paths, ranges := pageRanges(counts, page)
+
+	entries := make([]sitemapEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, sitemapEntry{path: path})
+	}
+
+	for _, r := range ranges {
+		records, err := findRange(e, r)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records {
+			entry := sitemapEntry{
+				path:     r.source.path(record),
+				modified: record.GetDateTime("updated").Time(),
+			}
+			if r.source.image != "" {
+				entry.image = record.GetString(r.source.image)
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// pageModified returns the latest update on the page, the first record of
+// every range is the newest of it
+func pageModified(e *core.RequestEvent, counts []int, page int) (time.Time, error) {
+	_, ranges := pageRanges(counts, page)
+
+	var latest time.Time
+	for _, r := range ranges {
+		r.limit = 1
+		records, err := findRange(e, r)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if len(records) > 0 && records[0].GetDateTime("updated").Time().After(latest) {
+			latest = records[0].GetDateTime("updated").Time()
+		}
+	}
+
+	return latest, nil
+}
+
+// sitemapIndex lists the sitemap pages
+func sitemapIndex(e *core.RequestEvent) error {
+	counts, err := sitemapCounts(e)
+	if err != nil {
+		return err
+	}
+
+	total := len(staticPages)
+	for _, count := range counts {
+		total += count
+	}
+
+	base := sites.URL(e)
+	doc := sitemapIndexDocument{}
+	var modified time.Time
+
+	for page := 1; (page-1)*sitemapPageSize < total; page++ {
+		lastMod, err := pageModified(e, counts, page)
+		if err != nil {
+			return err
+		}
+		if lastMod.After(modified) {
+			modified = lastMod
+		}
+
+		sitemap := sitemapRef{Loc: fmt.Sprintf("%s/sitemap-%d.xml", base, page)}
+		if !lastMod.IsZero() {
+			sitemap.LastMod = lastMod.UTC().Format(time.RFC3339)
+		}
+		doc.Sitemaps = append(doc.Sitemaps, sitemap)
+	}
+
+	body, err := encodeXML(doc)
+	if err != nil {
+		return err
+	}
+
+	return static.ServeContent(e, "application/xml; charset=utf-8", modified, body)
+}
+
+// serveSitemapPage serves the GET and HEAD requests of /sitemap-{n}.xml. Path
+// wildcards only match whole segments, so the pages are served by a middleware.
+func serveSitemapPage(e *core.RequestEvent) error {
+	if e.Request.Method != http.MethodGet && e.Request.Method != http.MethodHead {
+		return e.Next()
+	}
+
+	match := sitemapPagePattern.FindStringSubmatch(e.Request.URL.Path)
+	if match == nil {
+		return e.Next()
+	}
+
+	page, err := strconv.Atoi(match[1])
+	if err != nil {
+		return e.NotFoundError("", nil)
+	}
+
+	return sitemapPage(e, page)
+}
+
+// sitemapPage serves the 1-based sitemap page
+func sitemapPage(e *core.RequestEvent, page int) error {
+	counts, err := sitemapCounts(e)
+	if err != nil {
+		return err
+	}
+
+	entries, err := sitemapEntries(e, counts, page)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return e.NotFoundError("", nil)
+	}
+
+	base := sites.URL(e)
+	doc := urlSetDocument{Image: "http://www.google.com/schemas/sitemap-image/1.1"}
+	for _, entry := range entries {
+		u := sitemapURL{Loc: base + entry.path}
+		if !entry.modified.IsZero() {
+			u.LastMod = entry.modified.UTC().Format(time.RFC3339)
+		}
+		if entry.image != "" {
+			u.Image = &sitemapImage{Loc: sites.AbsoluteURL(base, entry.image)}
+		}
+		doc.URLs = append(doc.URLs, u)
+	}
+
+	body, err := encodeXML(doc)
+	if err != nil {
+		return err
+	}
+
+	return static.ServeContent(e, "application/xml; charset=utf-8", newest(entries), body)
+}
+
+// newest returns the latest modification time of the entries
+func newest(entries []sitemapEntry) time.Time {
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.modified.After(latest) {
+			latest = entry.modified
+		}
+	}
+	return latest
+}
@@ -0,0 +1,79 @@
+// guard.go
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/accounts"
+	"pocketbase/config"
+)
+
+// defaultAdminPassword is the fallback password of the initial superuser migration
+const defaultAdminPassword = accounts.DefaultPassword
+
+// minAdminPasswordLength is the shortest ADMIN_PASSWORD accepted in production
+const minAdminPasswordLength = 12
+
+// weakAdminPasswords are passwords that must never protect a superuser in production:
+// the migration default, the examples from the docs and the usual suspects
+var weakAdminPasswords = []string{
+	defaultAdminPassword,
+	"your-secure-password",
+	"your-password",
+	"admin",
+	"admin123",
+	"administrator",
+	"password",
+	"password123",
+	"changeme",
+	"123456",
+	"1234567890",
+	"qwerty",
+}
+
+// checkSuperuserPasswords refuses to continue when ADMIN_PASSWORD is weak or
+// any _superusers record uses the default or one of weakAdminPasswords. Every
+// comparison is a bcrypt hash, which only adds a moment to the boot.
+func checkSuperuserPasswords(app core.App, cfg *config.Config) error {
+	if password := cfg.Admin.Password; password != "" {
+		if isWeakPassword(password) || len(password) < minAdminPasswordLength {
+			return fmt.Errorf("ADMIN_PASSWORD is the default or a weak password, set a password of at least %d characters", minAdminPasswordLength)
+		}
+	}
+
+	superusers, err := app.FindAllRecords(core.CollectionNameSuperusers)
+	if err != nil {
+		return fmt.Errorf("failed to load superusers: %w", err)
+	}
+
+	var insecure []string
+	for _, superuser := range superusers {
+		for _, weak := range weakAdminPasswords {
+			if superuser.ValidatePassword(weak) {
+				insecure = append(insecure, superuser.Email())
+				break
+			}
+		}
+	}
+
+	if len(insecure) > 0 {
+		return fmt.Errorf(
+			"refusing to serve: superuser(s) %s use the default or a weak password; rotate it with `superuser upsert <email> <new-password>`",
+			strings.Join(insecure, ", "),
+		)
+	}
+
+	return nil
+}
+
+func isWeakPassword(password string) bool {
+	for _, weak := range weakAdminPasswords {
+		if strings.EqualFold(password, weak) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+// health/checks.go
+package health
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/security"
+
+	"pocketbase/migrations"
+)
+
+// Migrations checks that every registered app migration has been applied
+func Migrations() Check {
+	return Check{Name: "migrations", Run: func(ctx context.Context, app core.App) (string, error) {
+		var applied []string
+		err := app.DB().Select("file").From(core.DefaultMigrationsTable).WithContext(ctx).Column(&applied)
+		if err != nil {
+			return "", fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+
+		done := make(map[string]bool, len(applied))
+		for _, file := range applied {
+			done[file] = true
+		}
+
+		var pending []string
+		for _, migration := range core.AppMigrations.Items() {
+			if !done[migration.File] {
+				pending = append(pending, migration.File)
+			}
+		}
+
+		if len(pending) > 0 {
+			return "", fmt.Errorf("pending migrations: %s", strings.Join(pending, ", "))
+		}
+
+		return fmt.Sprintf("%d applied", len(core.AppMigrations.Items())), nil
+	}}
+}
+
+// Schema checks that every table and column declared in the schema file exists as
+// a collection field. The message is a hash of the declared schema, a failure
+// reports the hash of the live schema and what's missing from it.
+func Schema(path string) Check {
+	return Check{Name: "schema", Run: func(ctx context.Context, app core.App) (string, error) {
+		declared, err := migrations.ParseSQLFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		tables := make(map[string][]string, len(declared))
+		for _, table := range declared {
+			// the migration skips the "users" table as well
+			if table.Name == "users" {
+				continue
+			}
+			for _, column := range table.Columns {
+				tables[table.Name] = append(tables[table.Name], column.Name)
+			}
+		}
+
+		var expected, actual, missing []string
+		for table, columns := range tables {
+			collection, err := app.FindCollectionByNameOrId(table)
+			for _, column := range columns {
+				entry := table + "." + column
+				expected = append(expected, entry)
+
+				if err == nil && hasColumn(collection, column) {
+					actual = append(actual, entry)
+				} else {
+					missing = append(missing, entry)
+				}
+			}
+		}
+
+		expectedHash, actualHash := schemaHash(expected), schemaHash(actual)
+		if expectedHash != actualHash {
+			sort.Strings(missing)
+			if len(missing) > 5 {
+				missing = append(missing[:5], "...")
+			}
+			return "", fmt.Errorf("schema hash %s does not match %s, missing %s", actualHash, expectedHash, strings.Join(missing, ", "))
+		}
+
+		return "hash " + expectedHash, nil
+	}}
+}
+
+// hasColumn reports whether the collection has a field for the column. Auth collections
+// replace the auth columns of schema.sql (passwordHash, tokenKey, ...) with their system fields.
+func hasColumn(collection *core.Collection, column string) bool {
+	if collection.Fields.GetByName(column) != nil {
+		return true
+	}
+	if !collection.IsAuth() {
+		return false
+	}
+
+	if column == "passwordhash" {
+		column = core.FieldNamePassword
+	}
+	for _, field := range collection.Fields {
+		if strings.EqualFold(field.GetName(), column) {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaHash returns a short hash of the sorted entries
+func schemaHash(entries []string) string {
+	sorted := append([]string{}, entries...)
+	sort.Strings(sorted)
+
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\n")))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Record checks that the record with the given id exists in the collection
+func Record(name, collection, id string) Check {
+	return Check{Name: name, Run: func(ctx context.Context, app core.App) (string, error) {
+		if _, err := app.FindRecordById(collection, id); err != nil {
+			return "", fmt.Errorf("record %s/%s not found, run the seed command", collection, id)
+		}
+		return collection + "/" + id, nil
+	}}
+}
+
+// SMTP checks that the SMTP server accepts connections. Mail delivery that doesn't
+// use SMTP (the file mailer or SMTP disabled in the settings) passes with a note.
+// The check is optional, an unreachable mail server doesn't make the app unready.
+func SMTP(mailer string) Check {
+	return Check{Name: "smtp", Optional: true, Run: func(ctx context.Context, app core.App) (string, error) {
+		if mailer != "" && mailer != "smtp" {
+			return "not used, MAILER=" + mailer, nil
+		}
+
+		smtp := app.Settings().SMTP
+		if !smtp.Enabled {
+			return "disabled", nil
+		}
+
+		address := net.JoinHostPort(smtp.Host, strconv.Itoa(smtp.Port))
+
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err != nil {
+			return "", fmt.Errorf("SMTP server %s unreachable: %w", address, err)
+		}
+		conn.Close()
+
+		return address + " reachable", nil
+	}}
+}
+
+// Storage checks that files can be written to and deleted from the storage (local or S3)
+func Storage() Check {
+	return Check{Name: "storage", Run: func(ctx context.Context, app core.App) (string, error) {
+		fs, err := app.NewFilesystem()
+		if err != nil {
+			return "", fmt.Errorf("failed to open storage: %w", err)
+		}
+		defer fs.Close()
+
+		fs.SetContext(ctx)
+
+		key := "_ready_" + security.RandomString(10)
+		if err := fs.Upload([]byte("ok"), key); err != nil {
+			return "", fmt.Errorf("storage not writable: %w", err)
+		}
+		if err := fs.Delete(key); err != nil {
+			return "", fmt.Errorf("storage probe not deletable: %w", err)
+		}
+
+		if app.Settings().S3.Enabled {
+			return "s3 writable", nil
+		}
+		return "local writable", nil
+	}}
+}
@@ -0,0 +1,119 @@
+// health/ready.go
+//
+// Package health serves the readiness check of our own subsystems. PocketBase's
+// own /api/health only reports that the server is up and backs the Docker
+// healthcheck, /api/ready is meant for monitoring and deploy checks.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// ReadyPath is the route of the readiness check
+const ReadyPath = "/api/ready"
+
+// checkTimeout bounds every single check, so a hanging SMTP server can't block the readiness check
+const checkTimeout = 3 * time.Second
+
+// cacheTTL is how long the results are reused, the storage and SMTP probes
+// shouldn't run on every request of a monitoring system
+const cacheTTL = 10 * time.Second
+
+// Check is a single named readiness check, it returns a short status message
+// or an error when the subsystem isn't ready
+type Check struct {
+	Name string
+	Run  func(ctx context.Context, app core.App) (string, error)
+
+	// Optional checks are reported, but don't fail the readiness
+	Optional bool
+}
+
+// CheckResult is the JSON status of a single check
+type CheckResult struct {
+	OK      bool   `json:"ok"`
+	Message string `json:"message"`
+}
+
+// ReadyResponse is the JSON body of the readiness check
+type ReadyResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks,omitempty"`
+}
+
+// Register serves the readiness check with the given checks at ReadyPath.
+// The response is 200 when every required check passed and 503 otherwise.
+// Only superusers get the result of every check, the messages name hosts,
+// columns and migrations.
+func Register(app core.App, checks ...Check) {
+	cache := &readyCache{}
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET(ReadyPath, func(e *core.RequestEvent) error {
+			response := cache.get(e.App, checks)
+
+			status := http.StatusOK
+			if response.Status != "ok" {
+				status = http.StatusServiceUnavailable
+			}
+
+			if !e.HasSuperuserAuth() {
+				return e.JSON(status, ReadyResponse{Status: response.Status})
+			}
+
+			return e.JSON(status, response)
+		})
+
+		return se.Next()
+	})
+}
+
+// readyCache keeps the last results for cacheTTL
+type readyCache struct {
+	mu       sync.Mutex
+	checked  time.Time
+	response ReadyResponse
+}
+
+// get returns the cached results or runs the checks, concurrent requests wait for a single run.
+// The results are shared, so they don't depend on the context of the request that ran them:
+// a client that hangs up mustn't report "not ready" to everyone for cacheTTL.
+func (c *readyCache) get(app core.App, checks []Check) ReadyResponse {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if time.Since(c.checked) > cacheTTL {
+		c.response = Ready(context.Background(), app, checks)
+		c.checked = time.Now()
+	}
+
+	return c.response
+}
+
+// Ready runs the checks and collects their results
+func Ready(ctx context.Context, app core.App, checks []Check) ReadyResponse {
+	response := ReadyResponse{Status: "ok", Checks: make(map[string]CheckResult, len(checks))}
+
+	for _, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		message, err := check.Run(checkCtx, app)
+		cancel()
+
+		if err != nil {
+			if !check.Optional {
+				response.Status = "fail"
+			}
+			response.Checks[check.Name] = CheckResult{OK: false, Message: err.Error()}
+			continue
+		}
+
+		response.Checks[check.Name] = CheckResult{OK: true, Message: message}
+	}
+
+	return response
+}
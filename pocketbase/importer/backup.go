@@ -0,0 +1,189 @@
+// importer/backup.go
+package importer
+
+import (
+	"archive/zip"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackupSource reads the database and stored files of a PocketBase backup ZIP
+type BackupSource struct {
+	*SQLiteSource
+
+	tempDir    string
+	storageDir string
+
+	// collectionIds maps old collection names to their ids in the backup
+	collectionIds map[string]string
+}
+
+// OpenBackup extracts a PocketBase backup ZIP to a temporary directory
+// and opens its data.db as an import source
+func OpenBackup(zipFile string) (*BackupSource, error) {
+	// Extract backup to temporary directory
+	tempDir, err := os.MkdirTemp("", "pb_backup_import_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	src, err := openExtractedBackup(zipFile, tempDir)
+	if err != nil {
+		os.RemoveAll(tempDir)
+		return nil, err
+	}
+
+	return src, nil
+}
+
+func openExtractedBackup(zipFile, tempDir string) (*BackupSource, error) {
+	if err := extractBackup(zipFile, tempDir); err != nil {
+		return nil, fmt.Errorf("failed to extract backup: %w", err)
+	}
+
+	// Find the data.db file in the extracted backup
+	oldDBPath := filepath.Join(tempDir, "pb_data", "data.db")
+	if _, err := os.Stat(oldDBPath); os.IsNotExist(err) {
+		// Try alternative path
+		oldDBPath = filepath.Join(tempDir, "data.db")
+		if _, err := os.Stat(oldDBPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("could not find data.db in backup")
+		}
+	}
+
+	log.Printf("Found old database: %s", oldDBPath)
+
+	db, err := OpenSQLite(oldDBPath)
+	if err != nil {
+		return nil, err
+	}
+
+	src := &BackupSource{
+		SQLiteSource: db,
+		tempDir:      tempDir,
+	}
+
+	// Locate stored files next to data.db so they can be imported with their records
+	storageDir := filepath.Join(filepath.Dir(oldDBPath), "storage")
+	if info, err := os.Stat(storageDir); err != nil || !info.IsDir() {
+		log.Println("No storage directory found in backup, skipping file import")
+		return src, nil
+	}
+
+	collectionIds, err := getCollectionIds(db.db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to read old collection ids: %w", err)
+	}
+
+	src.storageDir = storageDir
+	src.collectionIds = collectionIds
+
+	return src, nil
+}
+
+// FilePath returns the local path of a stored file
+// (pb_data/storage/<collectionId>/<recordId>/<filename>)
+func (s *BackupSource) FilePath(table, recordId, name string) (string, bool) {
+	collectionId, ok := s.collectionIds[table]
+	if !ok {
+		return "", false
+	}
+
+	// The ids and names come from the backup, none of them may leave the storage directory
+	if !validIdentifier(collectionId) || !validIdentifier(recordId) || !validFileName(name) {
+		return "", false
+	}
+
+	path := filepath.Join(s.storageDir, collectionId, recordId, name)
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", false
+	}
+
+	return path, true
+}
+
+// validFileName reports whether name is a plain file name without directories
+func validFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name && !strings.Contains(name, "\\")
+}
+
+// Close closes the database and removes the extracted files
+func (s *BackupSource) Close() error {
+	err := s.SQLiteSource.Close()
+	os.RemoveAll(s.tempDir)
+	return err
+}
+
+// extractBackup extracts a ZIP backup file to the specified directory
+func extractBackup(zipFile, destDir string) error {
+	r, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for _, f := range r.File {
+		fpath := filepath.Join(destDir, f.Name)
+
+		// Check for ZipSlip vulnerability
+		if !strings.HasPrefix(fpath, filepath.Clean(destDir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid file path: %s", fpath)
+		}
+
+		if f.FileInfo().IsDir() {
+			os.MkdirAll(fpath, os.ModePerm)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(fpath), os.ModePerm); err != nil {
+			return err
+		}
+
+		outFile, err := os.OpenFile(fpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
+		if err != nil {
+			return err
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			outFile.Close()
+			return err
+		}
+
+		_, err = io.Copy(outFile, rc)
+		outFile.Close()
+		rc.Close()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getCollectionIds returns a map of collection names to ids from the old database
+func getCollectionIds(db *sql.DB) (map[string]string, error) {
+	rows, err := db.Query("SELECT id, name FROM _collections")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]string)
+	for rows.Next() {
+		var id, name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return nil, err
+		}
+		ids[name] = id
+	}
+
+	return ids, rows.Err()
+}
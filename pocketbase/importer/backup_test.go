@@ -0,0 +1,52 @@
+// importer/backup_test.go
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupFilePathStaysInStorage(t *testing.T) {
+	storage := filepath.Join(t.TempDir(), "storage")
+	if err := os.MkdirAll(filepath.Join(storage, "pbc_blogs", "record1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storage, "pbc_blogs", "record1", "image.png"), []byte("png"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// a file outside of the storage directory
+	if err := os.WriteFile(filepath.Join(storage, "..", "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := &BackupSource{
+		storageDir:    storage,
+		collectionIds: map[string]string{"blogs": "pbc_blogs", "escape": ".."},
+	}
+
+	scenarios := []struct {
+		name     string
+		table    string
+		recordId string
+		file     string
+		found    bool
+	}{
+		{"stored file", "blogs", "record1", "image.png", true},
+		{"unknown table", "tags", "record1", "image.png", false},
+		{"record id with parent dir", "blogs", "..", "../secret.txt", false},
+		{"record id with separators", "blogs", "record1/../..", "secret.txt", false},
+		{"file name with parent dir", "blogs", "record1", "../../../secret.txt", false},
+		{"file name with backslashes", "blogs", "record1", `..\\..\\secret.txt`, false},
+		{"collection id of the backup", "escape", "record1", "secret.txt", false},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			path, ok := src.FilePath(s.table, s.recordId, s.file)
+			if ok != s.found {
+				t.Fatalf("expected found %v, got %v (%q)", s.found, ok, path)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// importer/command.go
+package importer
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+)
+
+// Supported source formats
+const (
+	FormatBackup = "backup"
+	FormatSQLite = "sqlite"
+	FormatSQL    = "sql"
+	FormatNDJSON = "ndjson"
+	FormatJSON   = "json"
+	FormatCSV    = "csv"
+)
+
+// Open opens the file at path as an import source.
+// When format is empty it is detected from the file extension.
+func Open(path, format string) (Source, error) {
+	if format == "" {
+		format = DetectFormat(path)
+	}
+
+	switch format {
+	case FormatBackup:
+		return OpenBackup(path)
+	case FormatSQLite:
+		return OpenSQLite(path)
+	case FormatSQL:
+		return OpenSQLDump(path)
+	case FormatNDJSON:
+		return OpenNDJSON(path)
+	case FormatJSON:
+		return OpenJSON(path)
+	case FormatCSV:
+		return OpenCSV(path)
+	default:
+		return nil, fmt.Errorf("unsupported import format %q for %s", format, path)
+	}
+}
+
+// DetectFormat returns the source format for the file extension or an empty string
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return FormatBackup
+	case ".db", ".sqlite", ".sqlite3":
+		return FormatSQLite
+	case ".sql":
+		return FormatSQL
+	case ".ndjson", ".jsonl":
+		return FormatNDJSON
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	default:
+		return ""
+	}
+}
+
+// MustRegister registers the "import" command and panics if it fails.
+//
+// Example usage:
+//
+//	importer.MustRegister(app, app.RootCmd)
+func MustRegister(app core.App, rootCmd *cobra.Command) {
+	if err := Register(app, rootCmd); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the "import" command
+func Register(app core.App, rootCmd *cobra.Command) error {
+	if rootCmd == nil {
+		return fmt.Errorf("missing root command")
+	}
+
+	rootCmd.AddCommand(newImportCommand(app))
+
+	return nil
+}
+
+func newImportCommand(app core.App) *cobra.Command {
+	var format string
+	var tableMap []string
+	var batchSize int
+	var skipHooks bool
+
+	command := &cobra.Command{
+		Use:   "import <file>...",
+		Short: "Imports data from backups, SQLite files, SQL dumps, JSON or CSV files",
+		Long: `Imports data into the existing collections.
+
+Supported formats (detected from the file extension unless --format is set):
+  backup  PocketBase backup ZIP with stored files (.zip)
+  sqlite  plain SQLite database (.db, .sqlite, .sqlite3)
+  sql     SQL dump, loaded into an in-memory database (.sql)
+  ndjson  newline-delimited JSON, one table named after the file (.ndjson, .jsonl)
+  json    array of objects or an object of table arrays, Ghost exports included (.json)
+  csv     CSV with a header row, one table named after the file (.csv)`,
+		Example: `  import backups/pb_backup.zip
+  import old_blog.db
+  import ghost-export.json --map posts=blogs
+  import projects.csv --map projects=projects_valiantlynx
+  import likes.ndjson --batch-size 2000 --skip-hooks`,
+		Args:         cobra.MinimumNArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := Options{
+				TableMap:  make(map[string]string, len(tableMap)),
+				BatchSize: batchSize,
+				SkipHooks: skipHooks,
+			}
+			for _, pair := range tableMap {
+				from, to, ok := strings.Cut(pair, "=")
+				if !ok || from == "" || to == "" {
+					return fmt.Errorf("invalid --map value %q, expected source=collection", pair)
+				}
+				opts.TableMap[from] = to
+			}
+
+			for _, path := range args {
+				if err := importFile(app, path, format, opts); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.Flags().StringVar(&format, "format", "", "source format (backup, sqlite, sql, ndjson, json, csv)")
+	command.Flags().StringArrayVar(&tableMap, "map", nil, "map a source table to a collection (source=collection), can be repeated")
+	command.Flags().IntVar(&batchSize, "batch-size", DefaultBatchSize, "number of rows saved per transaction")
+	command.Flags().BoolVar(&skipHooks, "skip-hooks", false, "insert records without running app hooks (field types are still validated)")
+
+	return command
+}
+
+func importFile(app core.App, path, format string, opts Options) error {
+	src, err := Open(path, format)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	log.Printf("Importing %s", path)
+
+	if err := Import(app, src, opts); err != nil {
+		return err
+	}
+
+	log.Printf("Import of %s completed", path)
+	return nil
+}
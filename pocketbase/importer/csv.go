@@ -0,0 +1,121 @@
+// importer/csv.go
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// csvSource streams a CSV file with a header row as a single table
+type csvSource struct {
+	path    string
+	table   string
+	columns []string
+}
+
+// OpenCSV opens a CSV file with a header row as a single table named after the file.
+// Empty cells are treated as missing values.
+func OpenCSV(path string) (Source, error) {
+	file, reader, err := openCSV(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	header, err := readCSVHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &csvSource{path: path, table: tableNameFromPath(path), columns: header}, nil
+}
+
+// openCSV opens the file for reading records
+func openCSV(path string) (*os.File, *csv.Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	return file, reader, nil
+}
+
+// readCSVHeader reads the column names of the header row
+func readCSVHeader(reader *csv.Reader) ([]string, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make([]string, len(header))
+	for i, col := range header {
+		// Strip a UTF-8 BOM left by spreadsheet exports
+		columns[i] = strings.TrimSpace(strings.TrimPrefix(col, "\ufeff"))
+	}
+
+	return columns, nil
+}
+
+// Tables returns the single table of the file
+func (s *csvSource) Tables() ([]string, error) {
+	return []string{s.table}, nil
+}
+
+// Columns returns the columns of the header row
+func (s *csvSource) Columns(table string) ([]string, error) {
+	if table != s.table {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+
+	return s.columns, nil
+}
+
+// Rows reads the records of the file one at a time
+func (s *csvSource) Rows(table string, columns []string) (Rows, error) {
+	if table != s.table {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+
+	file, reader, err := openCSV(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := readCSVHeader(reader); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	next := func() (map[string]any, bool, error) {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		row := make(map[string]any, len(s.columns))
+		for i, value := range record {
+			if i < len(s.columns) && value != "" {
+				row[s.columns[i]] = value
+			}
+		}
+
+		return row, true, nil
+	}
+
+	return &streamRows{next: next, close: file.Close, columns: columns}, nil
+}
+
+// Close is a no-op, the file is only open while its rows are read
+func (s *csvSource) Close() error {
+	return nil
+}
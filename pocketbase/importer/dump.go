@@ -0,0 +1,187 @@
+// importer/dump.go
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// dumpStatements are the leading keywords a SQL dump may use. Everything else,
+// ATTACH, DETACH, PRAGMA and VACUUM INTO in particular, can read or write files
+// on the host and is rejected before anything is executed. DROP isn't needed
+// to load a dump into an empty database.
+var dumpStatements = map[string]bool{
+	"CREATE":    true,
+	"INSERT":    true,
+	"REPLACE":   true,
+	"UPDATE":    true,
+	"DELETE":    true,
+	"ALTER":     true,
+	"BEGIN":     true,
+	"COMMIT":    true,
+	"END":       true,
+	"ROLLBACK":  true,
+	"SAVEPOINT": true,
+	"RELEASE":   true,
+}
+
+// loadDump executes the statements of a SQL dump on the in-memory database
+func loadDump(db *sql.DB, dump string) error {
+	statements, err := splitStatements(dump)
+	if err != nil {
+		return err
+	}
+
+	var allowed []string
+	for i, stmt := range statements {
+		words := leadingWords(stmt, 2)
+		if len(words) == 0 {
+			continue
+		}
+
+		// sqlite3 .dump starts with "PRAGMA foreign_keys=OFF", it doesn't matter for a copy
+		if len(words) == 2 && words[0] == "PRAGMA" && strings.HasPrefix(words[1], "FOREIGN_KEYS") {
+			continue
+		}
+
+		if !dumpStatements[words[0]] {
+			return fmt.Errorf("statement %d: %s statements are not allowed in a SQL dump", i+1, words[0])
+		}
+
+		// Triggers would run their statements on every following insert, the rows are copied as they are
+		if isTrigger(stmt) {
+			log.Printf("Warning: skipping trigger of statement %d, triggers are not imported", i+1)
+			continue
+		}
+
+		allowed = append(allowed, stmt)
+	}
+
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// No other database files may be attached, even if a statement slipped through
+	if _, err := sqlite.Limit(conn, sqlite3.SQLITE_LIMIT_ATTACHED, 0); err != nil {
+		return err
+	}
+
+	for i, stmt := range allowed {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// splitStatements splits a SQL script at the semicolons outside of quotes,
+// comments and trigger bodies
+func splitStatements(script string) ([]string, error) {
+	var statements []string
+	start := 0
+
+	for i := 0; i < len(script); i++ {
+		switch c := script[i]; {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			end := strings.IndexByte(script[i+1:], closing)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated %c at offset %d", c, i)
+			}
+			// doubled quotes are escapes and simply continue the literal
+			i += end + 1
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			end := strings.IndexByte(script[i:], '\n')
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end
+			}
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", i)
+			}
+			i += end + 3
+		case c == ';':
+			stmt := script[start:i]
+			// the statements of a trigger body end with semicolons as well
+			if isTrigger(stmt) && !endsWithEnd(stmt) {
+				continue
+			}
+			statements = append(statements, strings.TrimSpace(stmt))
+			start = i + 1
+		}
+	}
+
+	if rest := strings.TrimSpace(script[start:]); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements, nil
+}
+
+// leadingWords returns up to n uppercased words at the start of the statement, skipping comments
+func leadingWords(stmt string, n int) []string {
+	var words []string
+	for len(words) < n {
+		stmt = strings.TrimLeftFunc(stmt, unicode.IsSpace)
+		switch {
+		case strings.HasPrefix(stmt, "--"):
+			if end := strings.IndexByte(stmt, '\n'); end >= 0 {
+				stmt = stmt[end:]
+				continue
+			}
+			return words
+		case strings.HasPrefix(stmt, "/*"):
+			if end := strings.Index(stmt, "*/"); end >= 0 {
+				stmt = stmt[end+2:]
+				continue
+			}
+			return words
+		}
+
+		end := strings.IndexFunc(stmt, func(r rune) bool {
+			return !unicode.IsLetter(r) && r != '_'
+		})
+		if end < 0 {
+			end = len(stmt)
+		}
+		if end == 0 {
+			return words
+		}
+		words = append(words, strings.ToUpper(stmt[:end]))
+		stmt = stmt[end:]
+	}
+	return words
+}
+
+// isTrigger reports whether the statement creates a trigger
+func isTrigger(stmt string) bool {
+	words := leadingWords(stmt, 3)
+	if len(words) < 2 || words[0] != "CREATE" {
+		return false
+	}
+	return words[1] == "TRIGGER" || (len(words) == 3 && (words[1] == "TEMP" || words[1] == "TEMPORARY") && words[2] == "TRIGGER")
+}
+
+// endsWithEnd reports whether the last word of the statement is END, closing a trigger body
+func endsWithEnd(stmt string) bool {
+	stmt = strings.TrimRightFunc(stmt, unicode.IsSpace)
+	return len(stmt) >= 3 && strings.EqualFold(stmt[len(stmt)-3:], "END") &&
+		(len(stmt) == 3 || !unicode.IsLetter(rune(stmt[len(stmt)-4])))
+}
@@ -0,0 +1,156 @@
+// importer/dump_test.go
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoadDumpRejectsStatements(t *testing.T) {
+	scenarios := []struct {
+		name string
+		dump string
+	}{
+		{"attach", `ATTACH DATABASE '/tmp/other.db' AS other;`},
+		{"detach", `DETACH DATABASE other;`},
+		{"pragma", `PRAGMA writable_schema = ON;`},
+		{"vacuum into", `VACUUM INTO '/tmp/copy.db';`},
+		{"drop", `CREATE TABLE posts (id TEXT); DROP TABLE posts;`},
+		{"lowercase", `attach database 'x.db' as x;`},
+		{"after a comment", `/* header */ -- line
+ATTACH DATABASE 'x.db' AS x;`},
+		{"hidden after a string", `CREATE TABLE posts (id TEXT); INSERT INTO posts VALUES (';'); ATTACH DATABASE 'x.db' AS x;`},
+		{"unterminated string", `INSERT INTO posts VALUES ('a);`},
+		{"unterminated comment", `/* ATTACH DATABASE 'x.db' AS x;`},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			if _, err := openDump(t, s.dump); err == nil {
+				t.Fatal("expected the dump to be rejected")
+			}
+		})
+	}
+}
+
+func TestLoadDumpSkipsTriggers(t *testing.T) {
+	src, err := openDump(t, `
+CREATE TABLE posts (id TEXT, title TEXT);
+CREATE TRIGGER posts_insert AFTER INSERT ON posts BEGIN
+	UPDATE posts SET title = 'changed'; DELETE FROM posts;
+END;
+INSERT INTO posts VALUES ('1', 'kept');
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	var title string
+	if err := src.db.QueryRow("SELECT title FROM posts").Scan(&title); err != nil {
+		t.Fatal(err)
+	}
+	if title != "kept" {
+		t.Fatalf("expected the trigger not to run, got title %q", title)
+	}
+
+	var triggers int
+	if err := src.db.QueryRow("SELECT count(*) FROM sqlite_master WHERE type = 'trigger'").Scan(&triggers); err != nil {
+		t.Fatal(err)
+	}
+	if triggers != 0 {
+		t.Fatalf("expected no triggers, got %d", triggers)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		script   string
+		expected []string
+	}{
+		{
+			"semicolons in string literals",
+			`INSERT INTO t VALUES ('a;b', 'it''s; fine'); INSERT INTO t VALUES ("c;d")`,
+			[]string{`INSERT INTO t VALUES ('a;b', 'it''s; fine')`, `INSERT INTO t VALUES ("c;d")`},
+		},
+		{
+			"semicolons in quoted identifiers",
+			"CREATE TABLE [a;b] (`c;d` TEXT);",
+			[]string{"CREATE TABLE [a;b] (`c;d` TEXT)"},
+		},
+		{
+			"semicolons in comments",
+			"-- one; two\nCREATE TABLE t (id TEXT /* a; b */);",
+			[]string{"-- one; two\nCREATE TABLE t (id TEXT /* a; b */)"},
+		},
+		{
+			"trigger bodies",
+			"CREATE TRIGGER x AFTER INSERT ON t BEGIN UPDATE t SET a = 1; DELETE FROM t; END; INSERT INTO t VALUES (1)",
+			[]string{"CREATE TRIGGER x AFTER INSERT ON t BEGIN UPDATE t SET a = 1; DELETE FROM t; END", "INSERT INTO t VALUES (1)"},
+		},
+	}
+
+	for _, s := range scenarios {
+		t.Run(s.name, func(t *testing.T) {
+			statements, err := splitStatements(s.script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(statements, s.expected) {
+				t.Fatalf("expected %q, got %q", s.expected, statements)
+			}
+		})
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	scenarios := []struct {
+		name  string
+		valid bool
+	}{
+		{"blogs", true},
+		{"users_valiantlynx", true},
+		{"_superusers", true},
+		{"", false},
+		{`posts"; DROP TABLE users; --`, false},
+		{`a"b`, false},
+		{"a'b", false},
+		{"a`b", false},
+		{"[posts]", false},
+		{"a]b", false},
+		{"a b", false},
+		{"a.b", false},
+		{"a;b", false},
+		{"../posts", false},
+	}
+
+	for _, s := range scenarios {
+		if got := validIdentifier(s.name); got != s.valid {
+			t.Errorf("validIdentifier(%q): expected %v, got %v", s.name, s.valid, got)
+		}
+	}
+
+	quoted := map[string]string{
+		"blogs": `"blogs"`,
+		`a"b`:   `"a""b"`,
+		`"`:     `""""`,
+	}
+	for name, expected := range quoted {
+		if got := quoteIdentifier(name); got != expected {
+			t.Errorf("quoteIdentifier(%q): expected %s, got %s", name, expected, got)
+		}
+	}
+}
+
+// openDump writes the dump to a temporary file and opens it
+func openDump(t *testing.T, dump string) (*SQLiteSource, error) {
+	path := filepath.Join(t.TempDir(), "dump.sql")
+	if err := os.WriteFile(path, []byte(strings.TrimSpace(dump)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return OpenSQLDump(path)
+}
@@ -0,0 +1,46 @@
+// importer/files.go
+package importer
+
+import (
+	"path/filepath"
+
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/pocketbase/pocketbase/tools/list"
+)
+
+// fileCopier copies stored files from a FileStore into the filesystem of the current app
+type fileCopier struct {
+	store FileStore
+	fs    *filesystem.System
+}
+
+// storedPaths returns the local paths of the filenames from the raw field value
+// that exist in the old record's storage directory
+func (c *fileCopier) storedPaths(table, oldRecordId string, raw any) []string {
+	var paths []string
+	for _, name := range list.ToUniqueStringSlice(raw) {
+		// Only plain filenames can reference stored files
+		if name == "" || name != filepath.Base(name) {
+			continue
+		}
+
+		if path, ok := c.store.FilePath(table, oldRecordId, name); ok {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// copyFile copies a stored file as-is into the given record files directory
+func (c *fileCopier) copyFile(path, baseFilesPath string) error {
+	f, err := filesystem.NewFileFromPath(path)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	f.OriginalName = name
+
+	return c.fs.UploadFile(f, baseFilesPath+"/"+name)
+}
@@ -0,0 +1,22 @@
+// importer/identifier.go
+package importer
+
+import (
+	"regexp"
+	"strings"
+)
+
+// identifierRegex matches the names PocketBase accepts for collections and fields.
+// Source tables and columns are read from untrusted files, so anything else is rejected
+// before it can end up in a query.
+var identifierRegex = regexp.MustCompile(`^\w+$`)
+
+// validIdentifier reports whether name is a safe table or column name
+func validIdentifier(name string) bool {
+	return identifierRegex.MatchString(name)
+}
+
+// quoteIdentifier quotes a table or column name for use in a SQLite query
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
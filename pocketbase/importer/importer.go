@@ -0,0 +1,529 @@
+// importer/importer.go
+//
+// Package importer copies data from external sources (PocketBase backups, plain
+// SQLite files, SQL dumps, JSON and CSV files) into the collections created from
+// schema.sql. Every source is exposed as a set of tables with named columns, rows
+// are matched to collections by table name and to fields by column name.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/filesystem"
+	"github.com/spf13/cast"
+
+	"pocketbase/timestamps"
+)
+
+// Source is a readable set of tables to import data from
+type Source interface {
+	// Tables returns the names of all tables in the source
+	Tables() ([]string, error)
+
+	// Columns returns the column names of the given table
+	Columns(table string) ([]string, error)
+
+	// Rows returns the values of the given columns for every row of the table
+	Rows(table string, columns []string) (Rows, error)
+
+	// Close releases any resources held by the source
+	Close() error
+}
+
+// Rows iterates over the rows returned by a Source
+type Rows interface {
+	// Next advances to the next row, returning false when there are no more rows
+	Next() bool
+
+	// Values returns the current row values in the order of the requested columns.
+	// Missing values are returned as nil.
+	Values() ([]any, error)
+
+	// Err returns the error, if any, that was encountered during iteration
+	Err() error
+
+	// Close stops the iteration
+	Close() error
+}
+
+// FileStore is implemented by sources that also carry uploaded files,
+// such as PocketBase backups
+type FileStore interface {
+	// FilePath returns the local path of a stored file of a source record
+	// and false if the file doesn't exist
+	FilePath(table, recordId, name string) (string, bool)
+}
+
+// importedSystemTables are the system tables imported like collections,
+// they hold accounts instead of PocketBase internals
+var importedSystemTables = map[string]bool{
+	core.CollectionNameSuperusers: true,
+}
+
+// DefaultBatchSize is the number of rows saved per transaction when not configured
+const DefaultBatchSize = 500
+
+// Options configures an import
+type Options struct {
+	// TableMap maps source table names to collection names.
+	// Tables without an entry are imported into the collection with the same name.
+	TableMap map[string]string
+
+	// BatchSize is the number of rows saved per transaction (default DefaultBatchSize)
+	BatchSize int
+
+	// SkipHooks inserts records directly without running the app hooks, which is
+	// much faster for bulk loads. Field values are still validated against their types.
+	// Records with files to upload are always saved normally.
+	SkipHooks bool
+}
+
+// Import copies the rows of all source tables that have a matching collection
+func Import(app core.App, src Source, opts Options) error {
+	tables, err := src.Tables()
+	if err != nil {
+		return fmt.Errorf("failed to get source tables: %w", err)
+	}
+
+	log.Printf("Found %d tables in source", len(tables))
+
+	// Get current collections
+	collections, err := app.FindAllCollections()
+	if err != nil {
+		return fmt.Errorf("failed to get current collections: %w", err)
+	}
+
+	// Map collection names for easier lookup
+	collectionMap := make(map[string]*core.Collection)
+	for _, col := range collections {
+		collectionMap[col.Name] = col
+	}
+
+	// Stored files are copied with their records when the source carries them
+	var files *fileCopier
+	if store, ok := src.(FileStore); ok {
+		fs, err := app.NewFilesystem()
+		if err != nil {
+			return fmt.Errorf("failed to open filesystem: %w", err)
+		}
+		defer fs.Close()
+
+		files = &fileCopier{store: store, fs: fs}
+	}
+
+	// Collects auth records that could not be carried over as-is
+	report := &authImportReport{}
+
+	// Import data for matching tables
+	for _, table := range tables {
+		// Skip system tables, except the ones holding user data
+		if (strings.HasPrefix(table, "_") && !importedSystemTables[table]) || strings.HasPrefix(table, "sqlite_") {
+			continue
+		}
+
+		// Names come from the source file and must follow the collection name rules
+		if !validIdentifier(table) {
+			log.Printf("Skipping table %q - invalid table name", table)
+			continue
+		}
+
+		collectionName := table
+		if mapped, ok := opts.TableMap[table]; ok {
+			collectionName = mapped
+		}
+
+		// Check if we have a matching collection
+		collection, exists := collectionMap[collectionName]
+		if !exists {
+			log.Printf("Skipping table '%s' - no matching collection in new schema", table)
+			continue
+		}
+
+		log.Printf("Importing data for table: %s", table)
+
+		// Get source table structure
+		columns, err := src.Columns(table)
+		if err != nil {
+			log.Printf("Warning: failed to get columns for %s: %v", table, err)
+			continue
+		}
+
+		// Get new collection fields
+		newFields := make(map[string]bool)
+		for _, field := range collection.Fields {
+			newFields[field.GetName()] = true
+		}
+
+		// Find matching columns
+		var matchingColumns []string
+		for _, col := range columns {
+			if !validIdentifier(col) {
+				log.Printf("Warning: skipping column %q in %s - invalid column name", col, table)
+				continue
+			}
+
+			if newFields[col] || (collection.IsAuth() && isLegacyAuthColumn(col)) {
+				matchingColumns = append(matchingColumns, col)
+			}
+		}
+
+		if len(matchingColumns) == 0 {
+			log.Printf("Warning: no matching columns found for table %s", table)
+			continue
+		}
+
+		// Import the data
+		if err := importTableData(app, src, table, collection, matchingColumns, opts, files, report); err != nil {
+			log.Printf("Warning: failed to import data for %s: %v", table, err)
+			continue
+		}
+
+		log.Printf("Successfully imported data for: %s", table)
+	}
+
+	report.log()
+
+	return nil
+}
+
+// importTableData imports data from a source table to the new collection.
+// Rows are saved in batches, each batch inside its own transaction.
+// When files is not nil, stored files referenced by the imported rows are copied as well.
+// Auth collections keep their password hashes and token keys, problems are collected in report.
+func importTableData(app core.App, src Source, tableName string, collection *core.Collection, columns []string, opts Options, files *fileCopier, report *authImportReport) error {
+	rows, err := src.Rows(tableName, columns)
+	if err != nil {
+		return fmt.Errorf("failed to query source data: %w", err)
+	}
+	defer rows.Close()
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	progress := newTableProgress(tableName)
+	batch := make([]*pendingRecord, 0, batchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := saveBatch(app, tableName, batch, opts, files, report, progress)
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+
+		progress.log()
+		return nil
+	}
+
+	for rows.Next() {
+		columnValues, err := rows.Values()
+		if err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		batch = append(batch, prepareRecord(tableName, collection, columns, columnValues, files))
+
+		if len(batch) >= batchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	progress.done()
+	return nil
+}
+
+// pendingRecord is a record prepared from a source row, waiting to be saved
+type pendingRecord struct {
+	record *core.Record
+
+	// The old record id is needed to locate its stored files
+	oldRecordId string
+
+	// Plain (non file field) values that point to stored files, copied after save
+	copyAfterSave []string
+	uploadCount   int
+
+	missingPassword bool
+}
+
+// prepareRecord creates a new unsaved record from the source row values
+func prepareRecord(tableName string, collection *core.Collection, columns []string, columnValues []any, files *fileCopier) *pendingRecord {
+	// Create a new record
+	p := &pendingRecord{record: core.NewRecord(collection)}
+	record := p.record
+
+	// Set field values
+	for i, colName := range columns {
+		val := columnValues[i]
+
+		// Handle NULL values
+		if val == nil {
+			continue
+		}
+
+		// Convert byte slices to strings for text fields
+		if b, ok := val.([]byte); ok {
+			val = string(b)
+		}
+
+		if colName == "id" {
+			p.oldRecordId = cast.ToString(val)
+		}
+
+		// Auth fields are carried over directly instead of going through the setters
+		if collection.IsAuth() && setAuthValue(record, colName, val) {
+			continue
+		}
+
+		// Set the field value
+		record.Set(colName, val)
+	}
+
+	// Non PocketBase sources usually lack the timestamps that schema.sql requires
+	timestamps.Fill(record)
+
+	// Without a password hash the user can't log in, so give them a random
+	// password to pass validation and flag the account for a reset
+	p.missingPassword = collection.IsAuth() && record.GetString(core.FieldNamePassword+":hash") == ""
+	if p.missingPassword {
+		record.SetRandomPassword()
+	}
+
+	if files == nil || p.oldRecordId == "" {
+		return p
+	}
+
+	for _, colName := range columns {
+		paths := files.storedPaths(tableName, p.oldRecordId, record.GetRaw(colName))
+		if len(paths) == 0 {
+			continue
+		}
+
+		// File fields get fresh uploads so PocketBase assigns the new filenames
+		if _, ok := collection.Fields.GetByName(colName).(*core.FileField); ok {
+			uploads := make([]*filesystem.File, 0, len(paths))
+			for _, path := range paths {
+				f, err := filesystem.NewFileFromPath(path)
+				if err != nil {
+					log.Printf("Warning: failed to read stored file %s for %s/%s: %v", path, tableName, p.oldRecordId, err)
+					continue
+				}
+				uploads = append(uploads, f)
+			}
+			record.Set(colName, uploads)
+			p.uploadCount += len(uploads)
+			continue
+		}
+
+		p.copyAfterSave = append(p.copyAfterSave, paths...)
+	}
+
+	return p
+}
+
+// saveBatch saves the pending records inside a single transaction.
+// Records that fail to save are logged and skipped, they don't abort the batch.
+func saveBatch(app core.App, tableName string, batch []*pendingRecord, opts Options, files *fileCopier, report *authImportReport, progress *tableProgress) error {
+	var saved []*pendingRecord
+
+	err := app.RunInTransaction(func(txApp core.App) error {
+		for _, p := range batch {
+			progress.processed++
+
+			var err error
+			if opts.SkipHooks && p.uploadCount == 0 {
+				err = insertRecord(txApp, p.record)
+			} else {
+				// Save the record (this will validate and apply defaults)
+				err = txApp.Save(p.record)
+			}
+
+			if err != nil {
+				// Log error but continue with other records
+				log.Printf("Warning: failed to import record in %s: %v", tableName, err)
+				progress.failed++
+				if p.record.Collection().IsAuth() {
+					report.failed = append(report.failed, authImportEntry{tableName, p.oldRecordId, p.record.Email(), err.Error()})
+				}
+				continue
+			}
+
+			saved = append(saved, p)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+
+	for _, p := range saved {
+		progress.imported++
+
+		if p.missingPassword {
+			report.passwordReset = append(report.passwordReset, authImportEntry{tableName, p.oldRecordId, p.record.Email(), "no password hash in source"})
+		}
+
+		// The new record may have a different id, so files go under its own storage path
+		progress.files += p.uploadCount
+		for _, path := range p.copyAfterSave {
+			if err := files.copyFile(path, p.record.BaseFilesPath()); err != nil {
+				log.Printf("Warning: failed to copy stored file %s for %s/%s: %v", path, tableName, p.oldRecordId, err)
+				continue
+			}
+			progress.files++
+		}
+	}
+
+	return nil
+}
+
+// insertRecord writes the record directly to its table without triggering
+// any model or record hooks. Field values are still validated against their
+// field types, but custom validation hooks and unique checks done in hooks are skipped.
+func insertRecord(app core.App, record *core.Record) error {
+	if record.Id == "" {
+		record.Set(core.FieldNameId+":autogenerate", "")
+	}
+
+	var errs []error
+	for _, field := range record.Collection().Fields {
+		if err := field.ValidateValue(context.Background(), app, record); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", field.GetName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	data, err := record.DBExport(app)
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB().Insert(record.Collection().Name, dbx.Params(data)).Execute()
+	if err != nil {
+		return err
+	}
+
+	record.MarkAsNotNew()
+	return nil
+}
+
+// tableProgress tracks and logs the import progress of a single table
+type tableProgress struct {
+	table     string
+	started   time.Time
+	processed int
+	imported  int
+	failed    int
+	files     int
+}
+
+func newTableProgress(table string) *tableProgress {
+	return &tableProgress{table: table, started: time.Now()}
+}
+
+// rate returns the number of processed rows per second
+func (p *tableProgress) rate() float64 {
+	elapsed := time.Since(p.started).Seconds()
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(p.processed) / elapsed
+}
+
+// log prints the current progress of the table
+func (p *tableProgress) log() {
+	log.Printf("%s: %d rows processed, %d imported, %d failed (%.0f rows/s)", p.table, p.processed, p.imported, p.failed, p.rate())
+}
+
+// done prints the final summary of the table
+func (p *tableProgress) done() {
+	log.Printf("Imported %d records and %d files into %s in %s (%d failed, %.0f rows/s)",
+		p.imported, p.files, p.table, time.Since(p.started).Round(time.Millisecond), p.failed, p.rate())
+}
+
+// isLegacyAuthColumn reports whether the column is an auth column
+// from an older PocketBase schema that has no direct field counterpart
+func isLegacyAuthColumn(column string) bool {
+	return column == "passwordHash"
+}
+
+// setAuthValue sets auth specific values on the record without triggering
+// the usual side effects (password hashing, token key regeneration).
+// It returns false if the column isn't an auth column.
+func setAuthValue(record *core.Record, column string, val any) bool {
+	switch column {
+	case core.FieldNamePassword, "passwordHash":
+		// the value is already a bcrypt hash, so it must not be hashed again
+		if hash := cast.ToString(val); hash != "" {
+			record.SetRaw(core.FieldNamePassword, &core.PasswordFieldValue{Hash: hash})
+		}
+	case core.FieldNameTokenKey:
+		if key := cast.ToString(val); key != "" {
+			record.SetTokenKey(key)
+		}
+	case core.FieldNameVerified:
+		record.SetVerified(cast.ToBool(val))
+	case core.FieldNameEmailVisibility:
+		record.SetEmailVisibility(cast.ToBool(val))
+	default:
+		return false
+	}
+
+	return true
+}
+
+// authImportEntry describes a single auth record that needs attention after the import
+type authImportEntry struct {
+	table  string
+	id     string
+	email  string
+	reason string
+}
+
+// authImportReport collects the auth records that could not be fully migrated
+type authImportReport struct {
+	failed        []authImportEntry
+	passwordReset []authImportEntry
+}
+
+// log prints the users that could not be migrated or need a password reset
+func (r *authImportReport) log() {
+	if len(r.failed) == 0 && len(r.passwordReset) == 0 {
+		return
+	}
+
+	for _, e := range r.failed {
+		log.Printf("User not migrated: %s/%s (%s): %s", e.table, e.id, e.email, e.reason)
+	}
+
+	for _, e := range r.passwordReset {
+		log.Printf("User migrated without password, reset required: %s/%s (%s): %s", e.table, e.id, e.email, e.reason)
+	}
+
+	log.Printf("Auth import: %d users not migrated, %d users require a password reset", len(r.failed), len(r.passwordReset))
+}
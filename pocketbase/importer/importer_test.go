@@ -0,0 +1,82 @@
+// importer/importer_test.go
+package importer
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tests"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestImportAuthRecordKeepsPassword(t *testing.T) {
+	const password = "imported-password-123"
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(map[string]any{
+		core.CollectionNameSuperusers: []map[string]any{{
+			"id":       "importedsuper01",
+			"email":    "imported@example.com",
+			"password": string(hash),
+			"tokenKey": "imported-token-key-0123456789abcdef",
+			"verified": true,
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	src, err := OpenJSON(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+
+	scenario := tests.ApiScenario{
+		Name:           "login with the password of the source",
+		Method:         http.MethodPost,
+		URL:            "/api/collections/_superusers/auth-with-password",
+		Body:           strings.NewReader(`{"identity":"imported@example.com","password":"` + password + `"}`),
+		ExpectedStatus: http.StatusOK,
+		ExpectedContent: []string{
+			`"token":`,
+			`"id":"importedsuper01"`,
+		},
+		TestAppFactory: func(t testing.TB) *tests.TestApp {
+			app, err := tests.NewTestApp()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := Import(app, src, Options{}); err != nil {
+				t.Fatal(err)
+			}
+
+			record, err := app.FindAuthRecordByEmail(core.CollectionNameSuperusers, "imported@example.com")
+			if err != nil {
+				t.Fatalf("superuser not imported: %v", err)
+			}
+			if record.TokenKey() != "imported-token-key-0123456789abcdef" {
+				t.Fatalf("expected the token key of the source, got %q", record.TokenKey())
+			}
+
+			return app
+		},
+	}
+
+	scenario.Test(t)
+}
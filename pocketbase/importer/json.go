@@ -0,0 +1,459 @@
+// importer/json.go
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// jsonLayout is where the tables of a JSON file are
+type jsonLayout int
+
+const (
+	// jsonArray is a top level array of objects, a single table named after the file
+	jsonArray jsonLayout = iota
+	// jsonObject maps table names to arrays of objects
+	jsonObject
+	// jsonGhost is a Ghost export, {"db": [{"data": {table: [...]}}]}
+	jsonGhost
+	// jsonLines is newline-delimited JSON, a single table named after the file
+	jsonLines
+)
+
+// maxJSONLine is the longest line of a newline-delimited JSON file, rows can hold rich content
+const maxJSONLine = 16 * 1024 * 1024
+
+// jsonSource streams the rows of a JSON or newline-delimited JSON file
+type jsonSource struct {
+	path   string
+	layout jsonLayout
+	tables map[string]*columnSet
+}
+
+// OpenNDJSON opens a newline-delimited JSON file as a single table named after the file.
+// Every non-empty line must be a JSON object.
+func OpenNDJSON(path string) (Source, error) {
+	s := &jsonSource{path: path, layout: jsonLines, tables: map[string]*columnSet{}}
+	table := s.table(tableNameFromPath(path))
+
+	file, scanner, err := openLines(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	for line := 1; scanner.Scan(); line++ {
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(data, &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		table.add(keys(row))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// OpenJSON opens a JSON document as an import source.
+//
+// A top level array of objects is a single table named after the file.
+// A top level object maps table names to arrays of objects, Ghost exports
+// ({"db": [{"data": {...}}]}) are unwrapped automatically.
+func OpenJSON(path string) (Source, error) {
+	s := &jsonSource{path: path, tables: map[string]*columnSet{}}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := s.scan(json.NewDecoder(bufio.NewReader(file))); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// table returns the columns of a table, registering it if needed
+func (s *jsonSource) table(name string) *columnSet {
+	if t, ok := s.tables[name]; ok {
+		return t
+	}
+	t := &columnSet{}
+	s.tables[name] = t
+	return t
+}
+
+// scan finds the layout, tables and columns of the document
+func (s *jsonSource) scan(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('['):
+		s.layout = jsonArray
+		return scanRows(dec, s.table(tableNameFromPath(s.path)))
+	case json.Delim('{'):
+		s.layout = jsonObject
+	default:
+		return fmt.Errorf("unsupported JSON document, expected an array or an object")
+	}
+
+	for dec.More() {
+		name, err := objectKey(dec)
+		if err != nil {
+			return err
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if tok != json.Delim('[') {
+			// Only arrays are tables
+			if err := skipValue(dec, tok); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if name == "db" {
+			ghost, err := s.scanGhost(dec)
+			if err != nil || ghost {
+				return err
+			}
+			continue
+		}
+
+		if err := scanRows(dec, s.table(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanGhost scans the "db" array, which is a Ghost export when its first
+// element has a data object. Otherwise it's a table named "db".
+func (s *jsonSource) scanGhost(dec *json.Decoder) (bool, error) {
+	table := s.table("db")
+	if !dec.More() {
+		_, err := dec.Token()
+		return false, err
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok != json.Delim('{') {
+		if err := skipValue(dec, tok); err != nil {
+			return false, err
+		}
+		return false, scanRows(dec, table)
+	}
+
+	// The first element is read key by key, the data object holds the whole export
+	var columns []string
+	for dec.More() {
+		key, err := objectKey(dec)
+		if err != nil {
+			return false, err
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+
+		if key == "data" && tok == json.Delim('{') {
+			s.layout = jsonGhost
+			s.tables = map[string]*columnSet{}
+			return true, s.scanGhostData(dec)
+		}
+
+		columns = append(columns, key)
+		if err := skipValue(dec, tok); err != nil {
+			return false, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return false, err
+	}
+
+	table.add(columns)
+	return false, scanRows(dec, table)
+}
+
+// scanGhostData scans the tables of the data object of a Ghost export
+func (s *jsonSource) scanGhostData(dec *json.Decoder) error {
+	for dec.More() {
+		name, err := objectKey(dec)
+		if err != nil {
+			return err
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		if tok != json.Delim('[') {
+			if err := skipValue(dec, tok); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := scanRows(dec, s.table(name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scanRows collects the columns of the objects of an array until its end, skipping other values
+func scanRows(dec *json.Decoder, table *columnSet) error {
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return err
+		}
+
+		var row map[string]json.RawMessage
+		if json.Unmarshal(raw, &row) == nil {
+			table.add(keys(row))
+		}
+	}
+
+	// closing bracket
+	_, err := dec.Token()
+	return err
+}
+
+// Tables returns the names of all tables in the source
+func (s *jsonSource) Tables() ([]string, error) {
+	tables := make([]string, 0, len(s.tables))
+	for name := range s.tables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+
+	return tables, nil
+}
+
+// Columns returns the column names of the given table
+func (s *jsonSource) Columns(table string) ([]string, error) {
+	t, ok := s.tables[table]
+	if !ok {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+
+	return t.columns, nil
+}
+
+// Rows reads the objects of the table from the file one at a time
+func (s *jsonSource) Rows(table string, columns []string) (Rows, error) {
+	if _, ok := s.tables[table]; !ok {
+		return nil, fmt.Errorf("unknown table %s", table)
+	}
+
+	if s.layout == jsonLines {
+		return s.lineRows(columns)
+	}
+
+	file, err := os.Open(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bufio.NewReader(file))
+	if err := s.seek(dec, table); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	next := func() (map[string]any, bool, error) {
+		for dec.More() {
+			var item any
+			if err := dec.Decode(&item); err != nil {
+				return nil, false, err
+			}
+			if row, ok := item.(map[string]any); ok {
+				return row, true, nil
+			}
+		}
+		return nil, false, nil
+	}
+
+	return &streamRows{next: next, close: file.Close, columns: columns}, nil
+}
+
+// lineRows reads the rows of a newline-delimited JSON file
+func (s *jsonSource) lineRows(columns []string) (Rows, error) {
+	file, scanner, err := openLines(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	next := func() (map[string]any, bool, error) {
+		for scanner.Scan() {
+			data := bytes.TrimSpace(scanner.Bytes())
+			if len(data) == 0 {
+				continue
+			}
+
+			var row map[string]any
+			if err := json.Unmarshal(data, &row); err != nil {
+				return nil, false, err
+			}
+			return row, true, nil
+		}
+		return nil, false, scanner.Err()
+	}
+
+	return &streamRows{next: next, close: file.Close, columns: columns}, nil
+}
+
+// seek moves the decoder to the first element of the table's array
+func (s *jsonSource) seek(dec *json.Decoder, table string) error {
+	var path []string
+	switch s.layout {
+	case jsonObject:
+		path = []string{table}
+	case jsonGhost:
+		path = []string{"db", "", "data", table}
+	}
+
+	for _, key := range path {
+		// "" is the first element of an array
+		open := json.Delim('{')
+		if key == "" {
+			open = '['
+		}
+		if err := expectDelim(dec, open); err != nil {
+			return err
+		}
+		if key != "" {
+			if err := seekKey(dec, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return expectDelim(dec, '[')
+}
+
+// seekKey skips the members of an object until key
+func seekKey(dec *json.Decoder, key string) error {
+	for dec.More() {
+		name, err := objectKey(dec)
+		if err != nil {
+			return err
+		}
+		if name == key {
+			return nil
+		}
+
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if err := skipValue(dec, tok); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("key %q not found", key)
+}
+
+// skipValue reads the rest of the value starting with tok
+func skipValue(dec *json.Decoder, tok json.Token) error {
+	if tok != json.Delim('{') && tok != json.Delim('[') {
+		return nil
+	}
+
+	for depth := 1; depth > 0; {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+
+	return nil
+}
+
+// objectKey reads the next member name of an object
+func objectKey(dec *json.Decoder) (string, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := tok.(string)
+	if !ok {
+		return "", fmt.Errorf("expected an object key, got %v", tok)
+	}
+	return key, nil
+}
+
+// expectDelim reads the next token, which must be delim
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expected %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// openLines opens a newline-delimited file for scanning
+func openLines(path string) (*os.File, *bufio.Scanner, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxJSONLine)
+
+	return file, scanner, nil
+}
+
+// keys returns the member names of a JSON object
+func keys(row map[string]json.RawMessage) []string {
+	names := make([]string, 0, len(row))
+	for name := range row {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Close is a no-op, the file is only open while its rows are read
+func (s *jsonSource) Close() error {
+	return nil
+}
@@ -0,0 +1,195 @@
+// importer/sqlite.go
+package importer
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	_ "modernc.org/sqlite" // SQLite driver
+)
+
+// SQLiteSource reads tables from a SQLite database
+type SQLiteSource struct {
+	db *sql.DB
+}
+
+// OpenSQLite opens a SQLite database file as an import source
+func OpenSQLite(path string) (*SQLiteSource, error) {
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	return &SQLiteSource{db: db}, nil
+}
+
+// OpenSQLDump loads a .sql dump into an in-memory SQLite database
+// and opens it as an import source. Only the statements of dumpStatements
+// are executed, a dump can't attach or write other database files.
+func OpenSQLDump(path string) (*SQLiteSource, error) {
+	dump, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open in-memory database: %w", err)
+	}
+
+	// Every connection gets its own in-memory database, so keep a single one
+	db.SetMaxOpenConns(1)
+
+	if err := loadDump(db, string(dump)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load SQL dump: %w", err)
+	}
+
+	return &SQLiteSource{db: db}, nil
+}
+
+// Tables returns a list of all tables in the database
+// whose names are valid collection names
+func (s *SQLiteSource) Tables() ([]string, error) {
+	tables, err := getTableList(s.db)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterIdentifiers(tables, "table"), nil
+}
+
+// Columns returns a list of column names for a given table
+// whose names are valid field names
+func (s *SQLiteSource) Columns(table string) ([]string, error) {
+	columns, err := getTableColumns(s.db, table)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterIdentifiers(columns, "column in "+table), nil
+}
+
+// Rows returns the values of the given columns for every row of the table
+func (s *SQLiteSource) Rows(table string, columns []string) (Rows, error) {
+	if !validIdentifier(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	// Build SELECT query with quoted identifiers
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		if !validIdentifier(col) {
+			return nil, fmt.Errorf("invalid column name %q in %s", col, table)
+		}
+		quoted[i] = quoteIdentifier(col)
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(quoted, ", "), quoteIdentifier(table))
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqlRows{rows: rows, size: len(columns)}, nil
+}
+
+// Close closes the underlying database
+func (s *SQLiteSource) Close() error {
+	return s.db.Close()
+}
+
+// sqlRows adapts *sql.Rows to the Rows interface
+type sqlRows struct {
+	rows *sql.Rows
+	size int
+}
+
+func (r *sqlRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *sqlRows) Values() ([]any, error) {
+	// Prepare scan destinations
+	columnValues := make([]any, r.size)
+	columnPointers := make([]any, r.size)
+	for i := range columnValues {
+		columnPointers[i] = &columnValues[i]
+	}
+
+	if err := r.rows.Scan(columnPointers...); err != nil {
+		return nil, err
+	}
+
+	return columnValues, nil
+}
+
+func (r *sqlRows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *sqlRows) Close() error {
+	return r.rows.Close()
+}
+
+// getTableList returns a list of all tables in the database
+func getTableList(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name FROM sqlite_master WHERE type='table' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tables []string
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		tables = append(tables, tableName)
+	}
+
+	return tables, rows.Err()
+}
+
+// getTableColumns returns a list of column names for a given table
+func getTableColumns(db *sql.DB, tableName string) ([]string, error) {
+	// The table-valued pragma takes the table name as a bound parameter
+	rows, err := db.Query("SELECT name FROM pragma_table_info(?) ORDER BY cid", tableName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rows.Err()
+}
+
+// filterIdentifiers drops and logs the names that aren't valid identifiers
+func filterIdentifiers(names []string, kind string) []string {
+	valid := make([]string, 0, len(names))
+	for _, name := range names {
+		if !validIdentifier(name) {
+			log.Printf("Warning: skipping %s with invalid name %q", kind, name)
+			continue
+		}
+		valid = append(valid, name)
+	}
+
+	return valid
+}
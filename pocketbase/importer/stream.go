@@ -0,0 +1,86 @@
+// importer/stream.go
+package importer
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// JSON and CSV files are streamed: they are scanned for their tables and columns
+// when opened, and read again for the rows of every table, so only the current
+// row is held in memory.
+
+// columnSet collects the columns of a table in order of appearance
+type columnSet struct {
+	columns []string
+	known   map[string]bool
+}
+
+// add registers the keys that weren't seen before. New keys are sorted so the
+// column order doesn't depend on map iteration.
+func (c *columnSet) add(keys []string) {
+	if c.known == nil {
+		c.known = make(map[string]bool)
+	}
+
+	var added []string
+	for _, key := range keys {
+		if !c.known[key] {
+			c.known[key] = true
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	c.columns = append(c.columns, added...)
+}
+
+// streamRows iterates over rows read one at a time from a file
+type streamRows struct {
+	// next returns the next row, false at the end of the table
+	next  func() (map[string]any, bool, error)
+	close func() error
+
+	columns []string
+	row     map[string]any
+	err     error
+}
+
+func (r *streamRows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	row, ok, err := r.next()
+	if err != nil {
+		r.err = err
+		return false
+	}
+
+	r.row = row
+	return ok
+}
+
+func (r *streamRows) Values() ([]any, error) {
+	values := make([]any, len(r.columns))
+	for i, col := range r.columns {
+		values[i] = r.row[col]
+	}
+
+	return values, nil
+}
+
+func (r *streamRows) Err() error {
+	return r.err
+}
+
+func (r *streamRows) Close() error {
+	return r.close()
+}
+
+// tableNameFromPath returns the file name without its extension
+func tableNameFromPath(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,118 @@
+// jobs/queue.go
+//
+// Package jobs runs background work (such as storing CSP reports) outside of
+// the request handlers and drains it in order when the app shuts down.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Default queue limits
+const (
+	DefaultWorkers   = 4
+	DefaultQueueSize = 1000
+)
+
+// Job is a unit of background work, ctx is canceled when the shutdown deadline passes
+type Job func(ctx context.Context) error
+
+type namedJob struct {
+	name string
+	run  Job
+}
+
+// Queue runs jobs on a fixed number of workers
+type Queue struct {
+	jobs chan namedJob
+	ctx  context.Context
+
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueue starts a queue with the given number of workers and buffered jobs
+func NewQueue(workers, size int) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &Queue{
+		jobs:   make(chan namedJob, size),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for i := 0; i < workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+
+	for job := range q.jobs {
+		if err := job.run(q.ctx); err != nil {
+			log.Printf("Warning: background job %s failed: %v", job.name, err)
+		}
+	}
+}
+
+// Enqueue schedules a job and reports whether it was accepted.
+// Jobs are rejected once the queue is full or draining.
+func (q *Queue) Enqueue(name string, job Job) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	select {
+	case q.jobs <- namedJob{name: name, run: job}:
+		return true
+	default:
+		log.Printf("Warning: background queue full, dropping job %s", name)
+		return false
+	}
+}
+
+// Len returns the number of jobs waiting for a worker
+func (q *Queue) Len() int {
+	return len(q.jobs)
+}
+
+// Drain stops accepting jobs and waits until the queued and running jobs finished.
+// When ctx is done first, the running jobs are canceled and an error with
+// the number of dropped jobs is returned.
+func (q *Queue) Drain(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		dropped := len(q.jobs)
+		q.cancel()
+		return fmt.Errorf("%d queued jobs dropped: %w", dropped, ctx.Err())
+	}
+}
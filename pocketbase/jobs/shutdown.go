@@ -0,0 +1,91 @@
+// jobs/shutdown.go
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Step is a single named shutdown action
+type Step struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Shutdown runs its steps in order when the app terminates, all within one deadline.
+//
+// PocketBase stops accepting requests before the steps run
+// and closes the database after they finished.
+type Shutdown struct {
+	timeout time.Duration
+	steps   []Step
+}
+
+// NewShutdown creates an empty shutdown sequence that must complete within timeout
+func NewShutdown(timeout time.Duration) *Shutdown {
+	return &Shutdown{timeout: timeout}
+}
+
+// Add appends a step, steps run in the order they were added
+func (s *Shutdown) Add(name string, run func(ctx context.Context) error) {
+	s.steps = append(s.steps, Step{Name: name, Run: run})
+}
+
+// Run executes the steps and logs the sequence. Once the deadline passes the
+// remaining steps are skipped, a step that ignores its canceled context is abandoned.
+func (s *Shutdown) Run() {
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	log.Printf("Shutting down (deadline %s)", s.timeout)
+
+	for i, step := range s.steps {
+		if ctx.Err() != nil {
+			log.Printf("Shutdown deadline exceeded, skipping %d remaining step(s)", len(s.steps)-i)
+			break
+		}
+
+		stepStarted := time.Now()
+		done := make(chan error, 1)
+		go func() {
+			done <- step.Run(ctx)
+		}()
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Printf("Shutdown: %s failed after %s: %v", step.Name, time.Since(stepStarted).Round(time.Millisecond), err)
+				continue
+			}
+			log.Printf("Shutdown: %s done in %s", step.Name, time.Since(stepStarted).Round(time.Millisecond))
+		case <-ctx.Done():
+			log.Printf("Shutdown: %s abandoned at the deadline", step.Name)
+		}
+	}
+
+	log.Printf("Shutdown completed in %s", time.Since(started).Round(time.Millisecond))
+}
+
+// Register runs the shutdown sequence when a serving app terminates.
+// Other commands (migrate, import, ...) don't start background work and exit as usual.
+func Register(app core.App, s *Shutdown) {
+	// set by the serve goroutine, read by the signal handler
+	var serving atomic.Bool
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		serving.Store(true)
+		return se.Next()
+	})
+
+	app.OnTerminate().BindFunc(func(te *core.TerminateEvent) error {
+		if serving.Load() && !te.IsRestart {
+			s.Run()
+		}
+		return te.Next()
+	})
+}
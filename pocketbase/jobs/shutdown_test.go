@@ -0,0 +1,121 @@
+// jobs/shutdown_test.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdownRunsStepsInOrder(t *testing.T) {
+	var ran []string
+	s := NewShutdown(time.Second)
+	for _, name := range []string{"drain", "flush", "close"} {
+		s.Add(name, func(ctx context.Context) error {
+			ran = append(ran, name)
+			if name == "flush" {
+				return errors.New("failed")
+			}
+			return nil
+		})
+	}
+
+	s.Run()
+
+	// a failing step doesn't stop the sequence
+	if want := []string{"drain", "flush", "close"}; !slices.Equal(ran, want) {
+		t.Fatalf("expected steps %v, got %v", want, ran)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	var mu sync.Mutex
+	var ran []string
+	record := func(name string) {
+		mu.Lock()
+		ran = append(ran, name)
+		mu.Unlock()
+	}
+
+	s := NewShutdown(50 * time.Millisecond)
+	s.Add("slow", func(ctx context.Context) error {
+		record("slow")
+		// ignores the canceled context, so it is abandoned
+		time.Sleep(time.Second)
+		return nil
+	})
+	s.Add("skipped", func(ctx context.Context) error {
+		record("skipped")
+		return nil
+	})
+
+	started := time.Now()
+	s.Run()
+
+	if elapsed := time.Since(started); elapsed > 500*time.Millisecond {
+		t.Fatalf("expected the shutdown to end at the deadline, took %s", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"slow"}; !slices.Equal(ran, want) {
+		t.Fatalf("expected steps %v, got %v", want, ran)
+	}
+}
+
+func TestQueueDrain(t *testing.T) {
+	q := NewQueue(2, 10)
+
+	var mu sync.Mutex
+	done := 0
+	for i := 0; i < 5; i++ {
+		q.Enqueue("job", func(ctx context.Context) error {
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			done++
+			mu.Unlock()
+			return nil
+		})
+	}
+
+	if err := q.Drain(context.Background()); err != nil {
+		t.Fatalf("expected the queue to drain, got %v", err)
+	}
+
+	if done != 5 {
+		t.Fatalf("expected 5 finished jobs, got %d", done)
+	}
+
+	if q.Enqueue("late", func(ctx context.Context) error { return nil }) {
+		t.Fatal("expected jobs to be rejected after the drain")
+	}
+}
+
+func TestQueueDrainDeadline(t *testing.T) {
+	q := NewQueue(1, 10)
+
+	canceled := make(chan struct{})
+	q.Enqueue("blocking", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	})
+	q.Enqueue("queued", func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := q.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline error, got %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the running job to be canceled")
+	}
+}
@@ -0,0 +1,77 @@
+// mail/command.go
+package mail
+
+import (
+	"fmt"
+	netmail "net/mail"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/spf13/cobra"
+
+	"pocketbase/config"
+)
+
+func newMailCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "mail",
+		Short: "Mail delivery helpers",
+	}
+
+	command.AddCommand(&cobra.Command{
+		Use:          "test <address>",
+		Short:        "Sends a test email using the current mail settings",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			to, err := netmail.ParseAddress(args[0])
+			if err != nil {
+				return fmt.Errorf("invalid address: %w", err)
+			}
+
+			return sendTestMail(app, *to)
+		},
+	})
+
+	return command
+}
+
+// sendTestMail sends a short message describing the delivery method in use
+func sendTestMail(app core.App, to netmail.Address) error {
+	settings := app.Settings()
+
+	method := deliveryMethod(app)
+
+	message := &mailer.Message{
+		From: netmail.Address{
+			Name:    settings.Meta.SenderName,
+			Address: settings.Meta.SenderAddress,
+		},
+		To:      []netmail.Address{to},
+		Subject: settings.Meta.AppName + " test email",
+		HTML: fmt.Sprintf(
+			"<p>This is a test email from %s (%s).</p><p>Delivery method: %s</p>",
+			settings.Meta.AppName, settings.Meta.AppURL, method,
+		),
+	}
+
+	if err := app.NewMailClient().Send(message); err != nil {
+		return fmt.Errorf("failed to send test email via %s: %w", method, err)
+	}
+
+	fmt.Printf("Test email sent to %s via %s\n", to.Address, method)
+	return nil
+}
+
+// deliveryMethod describes where outgoing emails currently end up
+func deliveryMethod(app core.App) string {
+	if cfg, err := config.Get(); err == nil && cfg.Mailer == MailerFile {
+		return "file " + Dir(app)
+	}
+
+	if smtp := app.Settings().SMTP; smtp.Enabled {
+		return fmt.Sprintf("SMTP %s:%d", smtp.Host, smtp.Port)
+	}
+
+	return "sendmail"
+}
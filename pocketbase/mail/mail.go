@@ -0,0 +1,145 @@
+// mail/mail.go
+//
+// Package mail configures how outgoing emails are delivered and adds the "mail" command.
+//
+// SMTP itself is configured through the SMTP_* settings variables (see config.ReconcileSettings).
+// With MAILER=file every outgoing message is written as an .eml file under pb_data/mails
+// instead of being sent, which is meant for local development and tests.
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/domodwyer/mailyak/v3"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/mailer"
+	"github.com/pocketbase/pocketbase/tools/security"
+	"github.com/spf13/cobra"
+
+	"pocketbase/config"
+)
+
+// Supported MAILER values
+const (
+	// MailerDefault sends through SMTP when enabled in the settings, otherwise sendmail
+	MailerDefault = "smtp"
+
+	// MailerFile writes every message as an .eml file under pb_data/mails
+	MailerFile = "file"
+)
+
+// MustRegister registers the mail delivery hook and the "mail" command and panics if it fails.
+//
+// Example usage:
+//
+//	mail.MustRegister(app, app.RootCmd)
+func MustRegister(app core.App, rootCmd *cobra.Command) {
+	if err := Register(app, rootCmd); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the mail delivery hook and the "mail" command
+func Register(app core.App, rootCmd *cobra.Command) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return err
+	}
+
+	switch mode := cfg.Mailer; mode {
+	case "", MailerDefault:
+		// nothing to change, PocketBase picks SMTP or sendmail from the settings
+	case MailerFile:
+		app.OnMailerSend().BindFunc(func(e *core.MailerEvent) error {
+			path, err := writeEML(Dir(e.App), e.Message)
+			if err != nil {
+				return fmt.Errorf("failed to write mail file: %w", err)
+			}
+
+			log.Printf("Mail written to %s", path)
+
+			// don't call e.Next() so the message is never handed to SMTP or sendmail
+			return nil
+		})
+	default:
+		return fmt.Errorf("unsupported MAILER %q, expected %q or %q", mode, MailerDefault, MailerFile)
+	}
+
+	if rootCmd != nil {
+		rootCmd.AddCommand(newMailCommand(app))
+	}
+
+	return nil
+}
+
+// Dir returns the directory the file mailer writes messages to
+func Dir(app core.App) string {
+	return filepath.Join(app.DataDir(), "mails")
+}
+
+// writeEML writes the message as an .eml file to dir and returns its path
+func writeEML(dir string, m *mailer.Message) (string, error) {
+	data, err := renderEML(m)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().UTC().Format("20060102T150405.000Z"), security.PseudorandomString(6))
+	path := filepath.Join(dir, name)
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// renderEML builds the raw MIME message the same way the SMTP client does
+func renderEML(m *mailer.Message) ([]byte, error) {
+	yak := mailyak.New("", nil)
+	yak.WriteBccHeader(true)
+
+	if m.From.Name != "" {
+		yak.FromName(m.From.Name)
+	}
+	yak.From(m.From.Address)
+	yak.Subject(m.Subject)
+	yak.HTML().Set(m.HTML)
+	yak.Plain().Set(m.Text)
+
+	for _, addr := range m.To {
+		yak.To(addr.String())
+	}
+	for _, addr := range m.Cc {
+		yak.Cc(addr.String())
+	}
+	for _, addr := range m.Bcc {
+		yak.Bcc(addr.String())
+	}
+
+	for name, data := range m.Attachments {
+		yak.Attach(name, data)
+	}
+	for name, data := range m.InlineAttachments {
+		yak.AttachInline(name, data)
+	}
+
+	for k, v := range m.Headers {
+		yak.AddHeader(k, v)
+	}
+
+	buf, err := yak.MimeBuf()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -3,15 +3,28 @@ package main
 
 import (
 	"log"
-	"os"
-	"path/filepath"
-	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/pocketbase/pocketbase"
 	"github.com/pocketbase/pocketbase/core"
 	"github.com/pocketbase/pocketbase/plugins/migratecmd"
 
+	"pocketbase/accounts"
+	"pocketbase/config"
+	"pocketbase/feeds"
+	"pocketbase/health"
+	"pocketbase/importer"
+	"pocketbase/jobs"
+	"pocketbase/mail"
+	"pocketbase/oauth"
+	"pocketbase/proxy"
+	"pocketbase/security"
+	"pocketbase/seed"
+	"pocketbase/seo"
+	"pocketbase/sites"
+	"pocketbase/static"
+
 	// Import your migrations package (enable this once you create migrations)
 	_ "pocketbase/migrations"
 )
@@ -22,49 +35,102 @@ func main() {
 
 	app := pocketbase.New()
 
-	// Check if running in development mode
-	isGoRun := strings.HasPrefix(os.Args[0], os.TempDir())
+	// Load the configuration from config.yaml, the environment and the command line flags
+	cfg, err := config.Init(app.RootCmd)
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 
 	// Register migrate command with auto-migration enabled in development
 	migratecmd.MustRegister(app, app.RootCmd, migratecmd.Config{
 		// Enable auto creation of migration files when making changes in Dashboard
-		// Only during development (when using "go run")
-		Automigrate: isGoRun,
+		// Only during development (APP_ENV=development)
+		Automigrate: cfg.IsDev(),
 	})
-	// serves static files from the provided public dir (if exists)
+
+	// Register import command for backups, SQLite files, SQL dumps, JSON and CSV
+	importer.MustRegister(app, app.RootCmd)
+
+	// Register seed command for schema.sql defaults and development fixtures
+	seed.MustRegister(app, app.RootCmd)
+
+	// Register mail delivery (MAILER=file for local development) and the mail command
+	mail.MustRegister(app, app.RootCmd)
+
+	// Configure OAuth2 providers (GOOGLE_*, GITHUB_*, DISCORD_*, FACEBOOK_*, OIDC_*)
+	oauth.Register(app, cfg)
+
+	// Apply the configured settings (APP_URL, SMTP_*, LOGS_*, ...) on every boot
+	app.OnBootstrap().BindFunc(func(e *core.BootstrapEvent) error {
+		if err := e.Next(); err != nil {
+			return err
+		}
+		return config.ReconcileSettings(e.App, cfg)
+	})
+
+	// Create superusers and editors added to ADMIN_EMAILS or ADMIN_SEED_FILE since the first start
 	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
-		// Create filesystem for pb_public directory
-		publicFS := os.DirFS("./pb_public")
-
-		// Add middleware to serve static files for non-API routes
-		se.Router.BindFunc(func(e *core.RequestEvent) error {
-			path := e.Request.URL.Path
-
-			// Skip API routes and admin routes - let them be handled by PocketBase
-			if strings.HasPrefix(path, "/api/") ||
-				strings.HasPrefix(path, "/_/") ||
-				strings.HasPrefix(path, "/admin") {
-				return e.Next()
-			}
+		if err := accounts.Seed(se.App, cfg); err != nil {
+			return err
+		}
+		return se.Next()
+	})
 
-			// Handle static file serving
-			filePath := strings.TrimPrefix(path, "/")
-			if filePath == "" {
-				filePath = "index.html"
+	// Readiness check for monitoring, details are only shown to superusers
+	health.Register(app,
+		health.Migrations(),
+		health.Schema(seed.SchemaFile),
+		health.Record(sites.DefaultId, sites.Collection, sites.DefaultId),
+		health.SMTP(cfg.Mailer),
+		health.Storage(),
+	)
+
+	// Background jobs (CSP reports) run on the queue and are drained after
+	// the server stopped accepting requests, within SHUTDOWN_TIMEOUT.
+	queue := jobs.NewQueue(jobs.DefaultWorkers, jobs.DefaultQueueSize)
+	shutdown := jobs.NewShutdown(time.Duration(cfg.ShutdownTimeout) * time.Second)
+	shutdown.Add("drain background jobs", queue.Drain)
+	jobs.Register(app, shutdown)
+
+	// Refuse to serve with the default or a weak superuser password outside of development
+	if !cfg.IsDev() {
+		app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+			if err := checkSuperuserPasswords(se.App, cfg); err != nil {
+				return err
 			}
+			return se.Next()
+		})
+	}
 
-			// Check if file exists in pb_public
-			if _, err := os.Stat(filepath.Join("./pb_public", filePath)); os.IsNotExist(err) {
-				// If file doesn't exist, serve index.html for SPA routing
-				filePath = "index.html"
-			}
+	// Real client IP and country behind Cloudflare or another reverse proxy
+	// (TRUSTED_PROXY_CIDRS), for the request logs, rate limits and proxy.FromRequest in hooks
+	proxy.Register(app, cfg.TrustedProxy)
 
-			// Serve the file using the FileFS method
-			return e.FileFS(publicFS, filePath)
-		})
+	// Resolve the site of every request by its host (sites.domain), scope blogs,
+	// projects and tags to it
+	sites.Register(app)
 
+	// Security headers and the Content-Security-Policy of the requested site (CSP_MODE, ...),
+	// after the site resolution. Violations are collected at /api/csp-report.
+	security.Register(app, cfg.Security, queue)
+
+	// Only CORS_ALLOWED_ORIGINS (or APP_URL) may call the API from browsers, feeds are
+	// open to any origin and sign-in and dashboard endpoints to the listed origins only
+	security.RegisterCORS(app, cfg.CORS, cfg.IsDev())
+
+	// sitemap.xml, robots.txt and the RSS, Atom and JSON feeds below /feeds
+	feeds.Register(app)
+
+	// Serve the built SvelteKit site with cache headers and precompressed variants,
+	// from PB_PUBLIC_DIR, the embedded files (go build -tags embed) or pb_public.
+	// Blog and project pages get their title and Open Graph/Twitter tags injected.
+	publicFS, publicSource := static.Source(cfg.PublicDir, embeddedPublic)
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		log.Printf("Serving the static site from %s", publicSource)
 		return se.Next()
 	})
+	static.Register(app, publicFS, seo.Render)
+
 	if err := app.Start(); err != nil {
 		log.Fatal(err)
 	}
@@ -95,11 +161,11 @@ BLOG-SVELTE ENHANCEMENTS:
 
 ENVIRONMENT CONFIGURATIONS:
 ✅ ADMIN_EMAIL - Admin account email
-✅ ADMIN_PASSWORD - Admin account password
+✅ ADMIN_PASSWORD - Admin account password (or ADMIN_PASSWORD_FILE for Docker secrets)
 ✅ APP_URL - Application URL
 ✅ Development vs production settings
 ✅ SMTP email configuration (optional)
-✅ OAuth2 client IDs and secrets (optional)
+✅ OAuth2 client IDs and secrets (optional, applied on every start)
 ✅ Custom data directory support
 
 INTEGRATION POINTS:
@@ -44,7 +44,7 @@ type ForeignKey struct {
 func init() {
 	m.Register(func(app core.App) error {
 		// Read and parse SQL file
-		tables, err := parseSQLFile("schema.sql")
+		tables, err := ParseSQLFile("schema.sql")
 		if err != nil {
 			return err
 		}
@@ -75,7 +75,8 @@ func init() {
 	})
 }
 
-func parseSQLFile(filename string) ([]SQLTable, error) {
+// ParseSQLFile returns the tables declared by the CREATE TABLE statements of filename
+func ParseSQLFile(filename string) ([]SQLTable, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, err
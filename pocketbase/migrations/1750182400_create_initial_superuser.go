@@ -2,55 +2,24 @@
 package migrations
 
 import (
-	"os"
-
 	"github.com/pocketbase/pocketbase/core"
 	m "github.com/pocketbase/pocketbase/migrations"
+
+	"pocketbase/accounts"
+	"pocketbase/config"
 )
 
 func init() {
 	m.Register(func(app core.App) error {
-		// Create initial superuser
-		superusersCollection, err := app.FindCollectionByNameOrId("_superusers")
+		cfg, err := config.Get()
 		if err != nil {
 			return err
 		}
 
-		// Get admin credentials from environment variables or use defaults
-		adminEmail := os.Getenv("ADMIN_EMAIL")
-		if adminEmail == "" {
-			adminEmail = "admin@valiantlynx.com"
-		}
-
-		adminPassword := os.Getenv("ADMIN_PASSWORD")
-		if adminPassword == "" {
-			adminPassword = "valiantlynx_admin_2025"
-		}
-
-		// Check if superuser already exists
-		if _, err := app.FindAuthRecordByEmail("_superusers", adminEmail); err == nil {
-			// Superuser already exists, skip creation
-			return nil
-		}
-
-		// Create new superuser record
-		record := core.NewRecord(superusersCollection)
-		record.Set("email", adminEmail)
-		record.Set("password", adminPassword)
-		record.Set("emailVisibility", true)
-		record.Set("verified", true)
-
-		if err := app.Save(record); err != nil {
-			return err
-		}
-
 		// Initialize application settings
 		settings := app.Settings()
 		settings.Meta.AppName = "Blog-Svelte"
-		settings.Meta.AppURL = os.Getenv("APP_URL")
-		if settings.Meta.AppURL == "" {
-			settings.Meta.AppURL = "http://localhost:8090"
-		}
+		settings.Meta.AppURL = "http://localhost:8090"
 		settings.Meta.SenderName = "Blog-Svelte Support"
 		settings.Meta.SenderAddress = "support@valiantlynx.com"
 
@@ -59,21 +28,20 @@ func init() {
 		settings.Logs.LogAuthId = true
 		settings.Logs.LogIP = true
 
+		// Configured overrides (APP_URL, SMTP_*, ...) win over the defaults above,
+		// they are also reapplied on every boot from main.go
+		config.ApplySettings(settings, cfg)
+
 		if err := app.Save(settings); err != nil {
 			return err
 		}
 
-		return nil
+		// Create the superusers from ADMIN_EMAIL and ADMIN_EMAILS and the accounts
+		// of ADMIN_SEED_FILE (after the settings, invite emails use the sender and URL).
+		// ADMIN_PASSWORD_FILE is supported for Docker secrets.
+		return accounts.Seed(app, cfg)
 	}, func(app core.App) error {
-		// Revert operation - remove the created superuser
-		adminEmail := os.Getenv("ADMIN_EMAIL")
-		if adminEmail == "" {
-			adminEmail = "admin@valiantlynx.com"
-		}
-
-		if record, err := app.FindAuthRecordByEmail("_superusers", adminEmail); err == nil {
-			app.Delete(record)
-		}
-		return nil
+		// Revert operation - remove only the accounts created from the configuration
+		return accounts.Remove(app)
 	})
 }
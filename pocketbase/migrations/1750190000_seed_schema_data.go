@@ -0,0 +1,26 @@
+// migrations/1750190000_seed_schema_data.go
+//
+// This migration applies the INSERT statements of schema.sql (the default site
+// and tags) once the collections exist, keeping the ids given there.
+//
+// The loader lives in the seed package, which also backs the "seed" command.
+package migrations
+
+import (
+	"log"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+
+	"pocketbase/seed"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		return seed.ApplyFile(app, seed.SchemaFile)
+	}, func(app core.App) error {
+		// Revert operation - the seeded records may have been edited since, keep them
+		log.Println("Seed data revert - no action taken (manual cleanup required)")
+		return nil
+	})
+}
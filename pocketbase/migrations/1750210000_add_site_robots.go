@@ -0,0 +1,18 @@
+// migrations/1750210000_add_site_robots.go
+//
+// This migration adds sites.robots_txt (custom robots.txt rules) to installs
+// created before it was declared in schema.sql.
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		return addFields(app, "sites", &core.TextField{Name: "robots_txt"})
+	}, func(app core.App) error {
+		return removeColumns(app, "sites", "robots_txt")
+	})
+}
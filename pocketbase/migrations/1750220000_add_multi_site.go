@@ -0,0 +1,55 @@
+// migrations/1750220000_add_multi_site.go
+//
+// This migration adds sites.domain (the hosts a site is served under) and the
+// site relation of blogs, projects and tags. Existing records are assigned to
+// the default site, so nothing changes until a second site is added.
+package migrations
+
+import (
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+
+	"pocketbase/sites"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		if err := addFields(app, sites.Collection, &core.TextField{Name: "domain"}); err != nil {
+			return err
+		}
+
+		collection, err := app.FindCollectionByNameOrId(sites.Collection)
+		if err != nil {
+			return err
+		}
+
+		for _, table := range sites.Scoped {
+			site := &core.RelationField{Name: "site", CollectionId: collection.Id, MaxSelect: 1}
+			if err := addFields(app, table, site); err != nil {
+				return err
+			}
+		}
+
+		if _, err := app.FindRecordById(sites.Collection, sites.DefaultId); err != nil {
+			// No default site (e.g. removed by hand), records without site stay unassigned
+			return nil
+		}
+
+		for _, table := range sites.Scoped {
+			_, err := app.DB().Update(table, dbx.Params{"site": sites.DefaultId}, dbx.HashExp{"site": ""}).Execute()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}, func(app core.App) error {
+		for _, table := range sites.Scoped {
+			if err := removeColumns(app, table, "site"); err != nil {
+				return err
+			}
+		}
+		return removeColumns(app, sites.Collection, "domain")
+	})
+}
@@ -0,0 +1,39 @@
+// migrations/1750230000_add_csp_reports.go
+//
+// This migration creates the csp_reports collection for installs created before
+// it was declared in schema.sql. The reports are stored by /api/csp-report and
+// only visible to superusers.
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+func init() {
+	m.Register(func(app core.App) error {
+		if err := addMissingTable(app, "schema.sql", "csp_reports"); err != nil {
+			return err
+		}
+
+		collection, err := app.FindCollectionByNameOrId("csp_reports")
+		if err != nil {
+			return err
+		}
+
+		// Superusers only, the reports are written by the collector
+		collection.ListRule = nil
+		collection.ViewRule = nil
+		collection.CreateRule = nil
+		collection.UpdateRule = nil
+		collection.DeleteRule = nil
+
+		return app.Save(collection)
+	}, func(app core.App) error {
+		collection, err := app.FindCollectionByNameOrId("csp_reports")
+		if err != nil {
+			return nil
+		}
+		return app.Delete(collection)
+	})
+}
@@ -0,0 +1,141 @@
+// migrations/1750240000_users_auth_collection.go
+//
+// This migration turns users_valiantlynx into an auth collection. The initial
+// migration creates every schema.sql table as a base collection, so nobody could
+// sign in and the OAuth2 providers had nothing to attach to.
+//
+// PocketBase can't change the type of a collection, so a new auth collection is
+// created, the records (with their ids and password hashes) are copied, the
+// relations are pointed at it and it takes over the name of the old collection.
+package migrations
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+	"github.com/pocketbase/pocketbase/tools/types"
+	"github.com/spf13/cast"
+)
+
+// usersCollection is the users table of schema.sql
+const usersCollection = "users_valiantlynx"
+
+// legacyAuthFields are the schema.sql columns replaced by the auth system fields
+var legacyAuthFields = map[string]bool{
+	"email":           true,
+	"emailvisibility": true,
+	"verified":        true,
+	"tokenkey":        true,
+	"passwordhash":    true,
+}
+
+func init() {
+	m.Register(func(app core.App) error {
+		old, err := app.FindCollectionByNameOrId(usersCollection)
+		if err != nil || old.IsAuth() {
+			return nil
+		}
+
+		users := core.NewAuthCollection(usersCollection + "_auth")
+		users.ListRule = old.ListRule
+		users.ViewRule = old.ViewRule
+		// anyone may sign up (OAuth2 creates users through this rule) and users manage
+		// themselves, the role is only assigned by superusers
+		users.CreateRule = types.Pointer("@request.body.role:isset = false")
+		users.UpdateRule = types.Pointer("id = @request.auth.id && @request.body.role:isset = false")
+		users.DeleteRule = types.Pointer("id = @request.auth.id")
+		users.PasswordAuth.IdentityFields = []string{core.FieldNameEmail, "username"}
+		users.AddIndex("idx_"+usersCollection+"_username", true, "username", "")
+
+		for _, field := range old.Fields {
+			switch name := field.GetName(); {
+			case legacyAuthFields[name] || name == core.FieldNameId:
+			case name == "created":
+				// sign ups and OAuth2 don't send the timestamps, they are set automatically
+				users.Fields.Add(&core.AutodateField{Name: name, OnCreate: true})
+			case name == "updated":
+				users.Fields.Add(&core.AutodateField{Name: name, OnCreate: true, OnUpdate: true})
+			default:
+				users.Fields.Add(field)
+			}
+		}
+
+		if err := app.Save(users); err != nil {
+			return err
+		}
+
+		if err := copyUsers(app, old, users); err != nil {
+			return err
+		}
+
+		references, err := app.FindCollectionReferences(old)
+		if err != nil {
+			return err
+		}
+		for collection, fields := range references {
+			for _, field := range fields {
+				if relation, ok := field.(*core.RelationField); ok {
+					relation.CollectionId = users.Id
+				}
+			}
+			// the relation collection can't be changed through validation, the column stays the same
+			if err := app.SaveNoValidate(collection); err != nil {
+				return err
+			}
+		}
+
+		if err := app.Delete(old); err != nil {
+			return err
+		}
+
+		users.Name = usersCollection
+		return app.Save(users)
+	}, func(app core.App) error {
+		// The auth collection is kept, the initial migration has no revert either
+		return nil
+	})
+}
+
+// copyUsers copies the records of the old users collection, keeping their ids,
+// password hashes and token keys so that existing sessions and relations stay valid
+func copyUsers(app core.App, old *core.Collection, users *core.Collection) error {
+	records, err := app.FindAllRecords(old)
+	if err != nil {
+		return err
+	}
+
+	for _, existing := range records {
+		record := core.NewRecord(users)
+		record.Id = existing.Id
+
+		for _, field := range old.Fields {
+			name := field.GetName()
+			if legacyAuthFields[name] || name == core.FieldNameId {
+				continue
+			}
+			record.SetRaw(name, existing.GetRaw(name))
+		}
+
+		record.SetEmail(existing.GetString("email"))
+		record.SetEmailVisibility(cast.ToBool(existing.Get("emailvisibility")))
+		record.SetVerified(cast.ToBool(existing.Get("verified")))
+
+		if hash := existing.GetString("passwordhash"); hash != "" {
+			record.SetRaw(core.FieldNamePassword, &core.PasswordFieldValue{Hash: hash})
+		} else {
+			record.SetRandomPassword()
+		}
+
+		// token keys shorter than the auth field minimum are replaced, signing the user out
+		if key := existing.GetString("tokenkey"); len(key) >= 30 {
+			record.SetTokenKey(key)
+		} else {
+			record.RefreshTokenKey()
+		}
+
+		if err := app.SaveNoValidate(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+// migrations/1750250000_site_rules.go
+//
+// This migration adds the site of the request to the list and view rules of
+// blogs, projects and tags. The site middleware sends it in the X-Site-Id
+// header, so lists, views and expands only return the records of that site.
+package migrations
+
+import (
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	m "github.com/pocketbase/pocketbase/migrations"
+)
+
+// siteRule matches the records of the request's site, records without site belong to the default site
+const siteRule = `(site = @request.headers.x_site_id || (site = "" && @request.headers.x_site_id = "default_site"))`
+
+// siteRuleCollections are the collections with a site relation
+var siteRuleCollections = []string{"blogs", "projects_valiantlynx", "tags"}
+
+func init() {
+	m.Register(func(app core.App) error {
+		return updateSiteRules(app, func(rule string) string {
+			switch {
+			case strings.Contains(rule, siteRule):
+				return rule
+			case rule == "":
+				return siteRule
+			default:
+				return "(" + rule + ") && " + siteRule
+			}
+		})
+	}, func(app core.App) error {
+		return updateSiteRules(app, func(rule string) string {
+			if rule == siteRule {
+				return ""
+			}
+			if inner, ok := strings.CutSuffix(rule, ") && "+siteRule); ok {
+				return strings.TrimPrefix(inner, "(")
+			}
+			return rule
+		})
+	})
+}
+
+// updateSiteRules changes the list and view rules of the collections, superuser only rules stay as they are
+func updateSiteRules(app core.App, update func(rule string) string) error {
+	for _, name := range siteRuleCollections {
+		collection, err := app.FindCollectionByNameOrId(name)
+		if err != nil {
+			return err
+		}
+
+		for _, rule := range []**string{&collection.ListRule, &collection.ViewRule} {
+			if *rule != nil {
+				value := update(**rule)
+				*rule = &value
+			}
+		}
+
+		if err := app.Save(collection); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
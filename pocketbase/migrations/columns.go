@@ -0,0 +1,84 @@
+// migrations/columns.go
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// addFields adds the fields the collection doesn't have yet. Migrations list their
+// fields instead of reading the schema file, which keeps changing after they ran.
+func addFields(app core.App, table string, fields ...core.Field) error {
+	collection, err := app.FindCollectionByNameOrId(table)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		if collection.Fields.GetByName(field.GetName()) == nil {
+			collection.Fields.Add(field)
+		}
+	}
+
+	return app.Save(collection)
+}
+
+// addMissingTable creates the collection of a table declared in the schema file
+// after the initial migration ran, with the same API rules it would have gotten there
+func addMissingTable(app core.App, filename string, table string) error {
+	tables, err := ParseSQLFile(filename)
+	if err != nil {
+		return err
+	}
+
+	for _, declared := range tables {
+		if declared.Name != table {
+			continue
+		}
+
+		if err := createCollectionWithoutRelations(app, declared); err != nil {
+			return err
+		}
+		return addRelationFields(app, declared)
+	}
+
+	return fmt.Errorf("table %s is not declared in %s", table, filename)
+}
+
+// columnField returns the field of a column, a relation for foreign keys
+func columnField(app core.App, table SQLTable, column SQLColumn) (core.Field, error) {
+	for _, fk := range table.ForeignKeys {
+		if fk.Column != column.Name {
+			continue
+		}
+
+		referenced, err := app.FindCollectionByNameOrId(fk.ReferencedTable)
+		if err != nil {
+			return nil, err
+		}
+
+		return &core.RelationField{
+			Name:         column.Name,
+			Required:     column.Required,
+			CollectionId: referenced.Id,
+			MaxSelect:    1,
+		}, nil
+	}
+
+	return createNonRelationField(column), nil
+}
+
+// removeColumns removes the fields of the collection, for reverting addFields
+func removeColumns(app core.App, table string, names ...string) error {
+	collection, err := app.FindCollectionByNameOrId(table)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		collection.Fields.RemoveByName(name)
+	}
+
+	return app.Save(collection)
+}
@@ -0,0 +1,170 @@
+// oauth/oauth.go
+//
+// Package oauth configures the OAuth2 providers of the users auth collection from
+// the configuration and maps provider profile data into new user records.
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/auth"
+
+	"pocketbase/accounts"
+	"pocketbase/config"
+)
+
+// Register configures the OAuth2 providers after the migrations ran
+// and maps provider profile data into new users
+func Register(app core.App, cfg *config.Config) {
+	collectionName := cfg.AuthCollection
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if err := ConfigureProviders(se.App, collectionName, cfg.OAuth2); err != nil {
+			return err
+		}
+		return se.Next()
+	})
+
+	app.OnRecordAuthWithOAuth2Request(collectionName).BindFunc(func(e *core.RecordAuthWithOAuth2RequestEvent) error {
+		if e.IsNewRecord {
+			fillProfile(e.OAuth2User)
+		}
+		return e.Next()
+	})
+}
+
+// ConfigureProviders applies the configured providers to the auth collection.
+// It fails when the collection is missing or not an auth collection, serving
+// would otherwise silently run without the configured sign in options.
+func ConfigureProviders(app core.App, collectionName string, oauth2 config.OAuth2Config) error {
+	collection, err := app.FindCollectionByNameOrId(collectionName)
+	if err != nil {
+		return fmt.Errorf("auth collection %s not found, run the migrations first: %w", collectionName, err)
+	}
+
+	if !collection.IsAuth() {
+		return fmt.Errorf("%s is not an auth collection, check AUTH_COLLECTION", collectionName)
+	}
+
+	providers := enabledProviders(oauth2)
+	if len(providers) == 0 {
+		return nil
+	}
+
+	before, _ := json.Marshal(collection.OAuth2)
+
+	for _, provider := range providers {
+		upsertProvider(&collection.OAuth2, provider)
+	}
+	collection.OAuth2.Enabled = true
+	mapKnownFields(collection)
+
+	after, _ := json.Marshal(collection.OAuth2)
+	if string(before) == string(after) {
+		return nil
+	}
+
+	if err := app.Save(collection); err != nil {
+		return fmt.Errorf("failed to save OAuth2 providers: %w", err)
+	}
+
+	names := make([]string, len(providers))
+	for i, provider := range providers {
+		names[i] = provider.Name
+	}
+	log.Printf("OAuth2 providers configured for %s: %s", collectionName, strings.Join(names, ", "))
+
+	return nil
+}
+
+// enabledProviders returns the providers that have credentials,
+// only the generic OIDC provider needs its endpoints and display name
+func enabledProviders(oauth2 config.OAuth2Config) []core.OAuth2ProviderConfig {
+	named := []struct {
+		name     string
+		provider config.OAuth2Provider
+	}{
+		{auth.NameGoogle, oauth2.Google},
+		{auth.NameGithub, oauth2.Github},
+		{auth.NameDiscord, oauth2.Discord},
+		{auth.NameFacebook, oauth2.Facebook},
+		{auth.NameOIDC, oauth2.OIDC},
+	}
+
+	var providers []core.OAuth2ProviderConfig
+	for _, n := range named {
+		if !n.provider.Enabled() {
+			continue
+		}
+
+		provider := core.OAuth2ProviderConfig{
+			Name:         n.name,
+			ClientId:     n.provider.ClientId,
+			ClientSecret: n.provider.ClientSecret,
+		}
+
+		if n.name == auth.NameOIDC {
+			provider.AuthURL = n.provider.AuthURL
+			provider.TokenURL = n.provider.TokenURL
+			provider.UserInfoURL = n.provider.UserInfoURL
+			provider.DisplayName = n.provider.DisplayName
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// upsertProvider replaces the provider with the same name or appends it
+func upsertProvider(cfg *core.OAuth2Config, provider core.OAuth2ProviderConfig) {
+	for i, existing := range cfg.Providers {
+		if existing.Name == provider.Name {
+			// keep options that can only be changed from the Admin UI
+			provider.PKCE = existing.PKCE
+			provider.Extra = existing.Extra
+			cfg.Providers[i] = provider
+			return
+		}
+	}
+
+	cfg.Providers = append(cfg.Providers, provider)
+}
+
+// mapKnownFields maps the provider name, username and avatar into
+// the collection fields of the same name, unless already mapped
+func mapKnownFields(collection *core.Collection) {
+	mapped := &collection.OAuth2.MappedFields
+
+	if mapped.Name == "" && collection.Fields.GetByName("name") != nil {
+		mapped.Name = "name"
+	}
+	if mapped.Username == "" && collection.Fields.GetByName("username") != nil {
+		mapped.Username = "username"
+	}
+	if mapped.AvatarURL == "" && collection.Fields.GetByName("avatar") != nil {
+		mapped.AvatarURL = "avatar"
+	}
+}
+
+// fillProfile fills in the profile data some providers don't return.
+// Google and OIDC providers usually have no username, so one is derived from
+// the email or name (PocketBase adds a random suffix if it's already taken).
+func fillProfile(user *auth.AuthUser) {
+	if user.Name == "" {
+		user.Name = strings.Split(user.Email, "@")[0]
+	}
+
+	if user.Username == "" {
+		source := strings.Split(user.Email, "@")[0]
+		if source == "" {
+			source = user.Name
+		}
+
+		user.Username = accounts.Username(source)
+	}
+}
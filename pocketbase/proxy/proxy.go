@@ -0,0 +1,204 @@
+// proxy/proxy.go
+//
+// Package proxy resolves the real client IP and country of requests that reach
+// PocketBase through trusted reverse proxies like Cloudflare, so the request
+// logs, the rate limits and hooks see the visitor instead of the proxy.
+package proxy
+
+import (
+	"log"
+	"net"
+	"net/netip"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+
+	"pocketbase/config"
+)
+
+// DefaultHeaders are checked in order when TRUSTED_PROXY_HEADERS is empty
+var DefaultHeaders = []string{"CF-Connecting-IP", "X-Real-IP", "X-Forwarded-For"}
+
+// middlewareId identifies the client resolution middleware
+const middlewareId = "trustedProxy"
+
+// requestKey stores the resolved client in the request event
+const requestKey = "client"
+
+// Client is the origin of a request
+type Client struct {
+	// IP is the visitor's address, the connection's address without a trusted proxy
+	IP string
+
+	// Country is the ISO 3166-1 alpha-2 code of the proxy's IP geolocation, empty when unknown
+	Country string
+
+	// Proxy is the address of the trusted proxy that forwarded the request
+	Proxy string
+}
+
+// FromRequest returns the client of the request
+func FromRequest(e *core.RequestEvent) Client {
+	if c, ok := e.Get(requestKey).(Client); ok {
+		return c
+	}
+	return Client{IP: e.RealIP()}
+}
+
+// RealIP returns the visitor's IP address of the request
+func RealIP(e *core.RequestEvent) string {
+	return FromRequest(e).IP
+}
+
+// Country returns the visitor's country code of the request, empty when unknown
+func Country(e *core.RequestEvent) string {
+	return FromRequest(e).Country
+}
+
+// Register adds the middleware that resolves the client of every request.
+//
+// With TRUSTED_PROXY_CIDRS the headers are only read from those proxies, the
+// resolved IP replaces the request's remote address (used by PocketBase's logs
+// and rate limits) and the headers of other clients are removed as spoofed.
+// Without it PocketBase's TRUSTED_PROXY_HEADERS handling applies unchanged and
+// the country header is always removed.
+func Register(app core.App, cfg config.TrustedProxyConfig) {
+	trusted := parseRanges(cfg.CIDRs)
+
+	headers := cfg.Headers
+	if len(headers) == 0 {
+		headers = DefaultHeaders
+	}
+	leftmost := cfg.UseLeftmostIP != nil && *cfg.UseLeftmostIP
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if len(trusted) == 0 {
+			if proxyHeaders := se.App.Settings().TrustedProxy.Headers; len(proxyHeaders) > 0 {
+				log.Printf("Warning: the client IP headers %s are trusted from any address, set TRUSTED_PROXY_CIDRS to the proxies", strings.Join(proxyHeaders, ", "))
+			}
+		} else {
+			log.Printf("Reading the client IP from %s of %d trusted proxy ranges", strings.Join(headers, ", "), len(trusted))
+		}
+
+		se.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id: middlewareId,
+			// before the CORS, activity logger and rate limit middlewares
+			Priority: apis.DefaultCorsMiddlewarePriority - 1,
+			Func: func(e *core.RequestEvent) error {
+				var client Client
+				if len(trusted) == 0 {
+					// Without trusted proxies any client could send the country
+					if cfg.CountryHeader != "" {
+						e.Request.Header.Del(cfg.CountryHeader)
+					}
+					client = Client{IP: e.RealIP()}
+				} else {
+					client = resolve(e, cfg.CountryHeader, headers, trusted, leftmost)
+				}
+				e.Set(requestKey, client)
+				setLogMeta(e, client)
+
+				return e.Next()
+			},
+		})
+
+		return se.Next()
+	})
+}
+
+// resolve reads the client from the headers of a trusted proxy and makes it the remote address
+func resolve(e *core.RequestEvent, countryHeader string, headers []string, trusted ranges, leftmost bool) Client {
+	remote, err := netip.ParseAddr(e.RemoteIP())
+	if err != nil || !trusted.contains(remote) {
+		// Sent by the client itself
+		removeHeaders(e, headers, countryHeader)
+		return Client{IP: e.RemoteIP()}
+	}
+
+	client := Client{IP: remote.String()}
+	for _, name := range headers {
+		if ip, ok := forwardedIP(e.Request.Header.Values(name), trusted, leftmost); ok {
+			client.IP = ip.String()
+			client.Proxy = remote.String()
+			break
+		}
+	}
+	client.Country = country(e, countryHeader)
+
+	// PocketBase's RealIP falls back to the remote address once its headers are gone
+	removeHeaders(e, headers)
+	_, port, _ := net.SplitHostPort(e.Request.RemoteAddr)
+	e.Request.RemoteAddr = net.JoinHostPort(client.IP, port)
+
+	return client
+}
+
+// forwardedIP returns the client of comma separated address lists (X-Forwarded-For):
+// the rightmost address that isn't a trusted proxy, or with leftmost the first one
+func forwardedIP(values []string, trusted ranges, leftmost bool) (netip.Addr, bool) {
+	var ips []netip.Addr
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			if ip, err := netip.ParseAddr(strings.TrimSpace(part)); err == nil {
+				ips = append(ips, ip.Unmap())
+			}
+		}
+	}
+	if len(ips) == 0 {
+		return netip.Addr{}, false
+	}
+
+	if !leftmost {
+		for i := len(ips) - 1; i >= 0; i-- {
+			if !trusted.contains(ips[i]) {
+				return ips[i], true
+			}
+		}
+	}
+
+	// Only proxies in the chain
+	return ips[0], true
+}
+
+// country returns the two letter country code of the header, Cloudflare sends XX when unknown
+func country(e *core.RequestEvent, header string) string {
+	if header == "" {
+		return ""
+	}
+	code := strings.ToUpper(strings.TrimSpace(e.Request.Header.Get(header)))
+	if len(code) != 2 || code == "XX" {
+		return ""
+	}
+	return code
+}
+
+// removeHeaders drops the proxy headers, PocketBase's TRUSTED_PROXY_HEADERS included
+func removeHeaders(e *core.RequestEvent, headers []string, extra ...string) {
+	for _, name := range e.App.Settings().TrustedProxy.Headers {
+		e.Request.Header.Del(name)
+	}
+	for _, name := range headers {
+		e.Request.Header.Del(name)
+	}
+	for _, name := range extra {
+		if name != "" {
+			e.Request.Header.Del(name)
+		}
+	}
+}
+
+// setLogMeta adds the country and, when IPs are logged, the proxy to the request log
+func setLogMeta(e *core.RequestEvent, client Client) {
+	meta := map[string]any{}
+	if client.Country != "" {
+		meta["country"] = client.Country
+	}
+	if client.Proxy != "" && e.App.Settings().Logs.LogIP {
+		meta["proxyIP"] = client.Proxy
+	}
+	if len(meta) > 0 {
+		e.Set(apis.RequestEventKeyLogMeta, meta)
+	}
+}
@@ -0,0 +1,85 @@
+// proxy/ranges.go
+package proxy
+
+import (
+	"net/netip"
+
+	"pocketbase/config"
+)
+
+// CloudflareRanges are Cloudflare's edge addresses (https://www.cloudflare.com/ips/)
+var CloudflareRanges = []string{
+	"173.245.48.0/20",
+	"103.21.244.0/22",
+	"103.22.200.0/22",
+	"103.31.4.0/22",
+	"141.101.64.0/18",
+	"108.162.192.0/18",
+	"190.93.240.0/20",
+	"188.114.96.0/20",
+	"197.234.240.0/22",
+	"198.41.128.0/17",
+	"162.158.0.0/15",
+	"104.16.0.0/13",
+	"104.24.0.0/14",
+	"172.64.0.0/13",
+	"131.0.72.0/22",
+	"2400:cb00::/32",
+	"2606:4700::/32",
+	"2803:f800::/32",
+	"2405:b500::/32",
+	"2405:8100::/32",
+	"2a06:98c0::/29",
+	"2c0f:f248::/32",
+}
+
+// PrivateRanges are the loopback and private networks, e.g. a reverse proxy
+// in the same Docker network
+var PrivateRanges = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// ranges is a set of trusted proxy networks
+type ranges []netip.Prefix
+
+// parseRanges expands the TRUSTED_PROXY_CIDRS entries, single IPs included.
+// The entries were validated with the configuration.
+func parseRanges(entries []string) ranges {
+	var values []string
+	for _, entry := range entries {
+		switch entry {
+		case config.ProxyCloudflare:
+			values = append(values, CloudflareRanges...)
+		case config.ProxyPrivate:
+			values = append(values, PrivateRanges...)
+		default:
+			values = append(values, entry)
+		}
+	}
+
+	result := make(ranges, 0, len(values))
+	for _, value := range values {
+		if prefix, err := netip.ParsePrefix(value); err == nil {
+			result = append(result, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(value); err == nil {
+			result = append(result, netip.PrefixFrom(addr, addr.BitLen()))
+		}
+	}
+	return result
+}
+
+// contains reports whether addr belongs to one of the networks
+func (r ranges) contains(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range r {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
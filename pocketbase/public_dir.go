@@ -0,0 +1,10 @@
+// public_dir.go
+//
+//go:build !embed
+
+package main
+
+import "io/fs"
+
+// embeddedPublic is nil without the embed build tag, the site is then read from pb_public
+var embeddedPublic fs.FS
@@ -0,0 +1,24 @@
+// public_embed.go
+//
+//go:build embed
+
+package main
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:pb_public
+var publicFiles embed.FS
+
+// embeddedPublic is the static site compiled into the binary (go build -tags embed)
+var embeddedPublic = mustSub(publicFiles, "pb_public")
+
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
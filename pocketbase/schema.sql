@@ -35,7 +35,9 @@ CREATE TABLE blogs (
     views INTEGER DEFAULT 0,
     likes INTEGER DEFAULT 0,
     published INTEGER DEFAULT 1,
-    FOREIGN KEY (author) REFERENCES users_valiantlynx(id) ON DELETE CASCADE
+    site TEXT DEFAULT '',
+    FOREIGN KEY (author) REFERENCES users_valiantlynx(id) ON DELETE CASCADE,
+    FOREIGN KEY (site) REFERENCES sites(id) ON DELETE SET NULL
 );
 
 CREATE TABLE projects_valiantlynx (
@@ -50,7 +52,9 @@ CREATE TABLE projects_valiantlynx (
     user TEXT NOT NULL,
     featured INTEGER DEFAULT 0,
     active INTEGER DEFAULT 1,
-    FOREIGN KEY (user) REFERENCES users_valiantlynx(id) ON DELETE CASCADE
+    site TEXT DEFAULT '',
+    FOREIGN KEY (user) REFERENCES users_valiantlynx(id) ON DELETE CASCADE,
+    FOREIGN KEY (site) REFERENCES sites(id) ON DELETE SET NULL
 );
 
 CREATE TABLE tags (
@@ -60,7 +64,9 @@ CREATE TABLE tags (
     name TEXT UNIQUE NOT NULL,
     slug TEXT UNIQUE NOT NULL,
     description TEXT DEFAULT '',
-    color TEXT DEFAULT '#3B82F6'
+    color TEXT DEFAULT '#3B82F6',
+    site TEXT DEFAULT '',
+    FOREIGN KEY (site) REFERENCES sites(id) ON DELETE SET NULL
 );
 
 CREATE TABLE sites (
@@ -79,7 +85,9 @@ CREATE TABLE sites (
     twitter_handle TEXT DEFAULT '',
     facebook_url TEXT DEFAULT '',
     github_url TEXT DEFAULT '',
-    linkedin_url TEXT DEFAULT ''
+    linkedin_url TEXT DEFAULT '',
+    robots_txt TEXT DEFAULT '',
+    domain TEXT DEFAULT ''
 );
 
 CREATE TABLE likes (
@@ -146,6 +154,21 @@ CREATE TABLE messages (
     FOREIGN KEY (recipient) REFERENCES users_valiantlynx(id) ON DELETE CASCADE
 );
 
+CREATE TABLE csp_reports (
+    id TEXT PRIMARY KEY DEFAULT ('csp_' || lower(hex(randomblob(7)))),
+    created DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated DATETIME NOT NULL DEFAULT (datetime('now')),
+    document_uri TEXT DEFAULT '',
+    blocked_uri TEXT DEFAULT '',
+    effective_directive TEXT DEFAULT '',
+    original_policy TEXT DEFAULT '',
+    disposition TEXT DEFAULT '',
+    source_file TEXT DEFAULT '',
+    line_number INTEGER DEFAULT 0,
+    sample TEXT DEFAULT '',
+    user_agent TEXT DEFAULT ''
+);
+
 CREATE TRIGGER IF NOT EXISTS update_users_valiantlynx_timestamp 
 AFTER UPDATE ON users_valiantlynx
 BEGIN
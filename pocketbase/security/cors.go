@@ -0,0 +1,227 @@
+// security/cors.go
+package security
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/hook"
+
+	"pocketbase/config"
+	"pocketbase/sites"
+)
+
+// corsMiddlewareId replaces PocketBase's CORS middleware, which allows any origin
+const corsMiddlewareId = "corsAllowlist"
+
+// DevOrigins are the SvelteKit dev and preview servers, allowed in development
+// when CORS_ALLOWED_ORIGINS is empty
+var DevOrigins = []string{"http://localhost:5173", "http://localhost:4173"}
+
+// corsMethods may be used by the allowed origins, open routes are read only
+var (
+	corsMethods     = []string{http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodPost, http.MethodDelete}
+	corsOpenMethods = []string{http.MethodGet, http.MethodHead}
+)
+
+// CORSPolicy decides which origins may call a route
+type CORSPolicy int
+
+const (
+	// CORSAllowlist allows the configured origins, wildcards included
+	CORSAllowlist CORSPolicy = iota
+
+	// CORSOpen allows any origin to read, without credentials
+	CORSOpen
+
+	// CORSRestricted only allows the exactly listed origins (and APP_URL),
+	// "*" and subdomain wildcards don't apply
+	CORSRestricted
+)
+
+// CORSRule applies Policy to the paths starting with Prefix or matching Pattern (path.Match)
+type CORSRule struct {
+	Prefix  string
+	Pattern string
+	Policy  CORSPolicy
+}
+
+// CORSRules are checked in order, the first match wins and CORSAllowlist applies to the rest
+var CORSRules = []CORSRule{
+	// Read by feed readers, crawlers and embeds on any site
+	{Prefix: "/feeds/", Policy: CORSOpen},
+	{Prefix: "/sitemap.xml", Policy: CORSOpen},
+	{Pattern: "/sitemap-*.xml", Policy: CORSOpen},
+	{Prefix: "/robots.txt", Policy: CORSOpen},
+	{Prefix: sites.ConfigPath, Policy: CORSOpen},
+
+	// Signing in and account changes
+	{Pattern: "/api/collections/*/auth-*", Policy: CORSRestricted},
+	{Pattern: "/api/collections/*/request-*", Policy: CORSRestricted},
+	{Pattern: "/api/collections/*/confirm-*", Policy: CORSRestricted},
+	{Pattern: "/api/collections/*/impersonate/*", Policy: CORSRestricted},
+	{Prefix: "/api/oauth2-redirect", Policy: CORSRestricted},
+	{Prefix: "/api/collections/_superusers/", Policy: CORSRestricted},
+
+	// The dashboard APIs: collections, settings, backups, logs and crons
+	{Pattern: "/api/collections", Policy: CORSRestricted},
+	{Pattern: "/api/collections/*", Policy: CORSRestricted},
+	{Pattern: "/api/collections/*/truncate", Policy: CORSRestricted},
+	{Prefix: "/api/collections/meta/", Policy: CORSRestricted},
+	{Prefix: "/api/settings", Policy: CORSRestricted},
+	{Prefix: "/api/backups", Policy: CORSRestricted},
+	{Prefix: "/api/logs", Policy: CORSRestricted},
+	{Prefix: "/api/crons", Policy: CORSRestricted},
+	{Prefix: "/_/", Policy: CORSRestricted},
+}
+
+// corsPolicy returns the policy of the request path
+func corsPolicy(p string) CORSPolicy {
+	for _, rule := range CORSRules {
+		if rule.Prefix != "" && strings.HasPrefix(p, rule.Prefix) {
+			return rule.Policy
+		}
+		if rule.Pattern != "" {
+			if ok, _ := path.Match(rule.Pattern, p); ok {
+				return rule.Policy
+			}
+		}
+	}
+	return CORSAllowlist
+}
+
+// RegisterCORS replaces PocketBase's CORS middleware (any origin) with the
+// allowlist of cfg and the per route CORSRules. dev adds DevOrigins when
+// no origins are configured.
+func RegisterCORS(app core.App, cfg config.CORSConfig, dev bool) {
+	origins := cfg.AllowedOrigins
+	if len(origins) == 0 && dev {
+		origins = DevOrigins
+	}
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		if len(cfg.AllowedOrigins) == 0 {
+			allowed := append([]string{se.App.Settings().Meta.AppURL}, origins...)
+			log.Printf("CORS_ALLOWED_ORIGINS is not set, allowing %s", strings.Join(allowed, ", "))
+		}
+
+		se.Router.Unbind(apis.DefaultCorsMiddlewareId)
+		se.Router.Bind(&hook.Handler[*core.RequestEvent]{
+			Id: corsMiddlewareId,
+			// before the rate limit, preflight requests aren't counted
+			Priority: apis.DefaultCorsMiddlewarePriority,
+			Func: func(e *core.RequestEvent) error {
+				return handleCORS(e, cfg, origins)
+			},
+		})
+
+		return se.Next()
+	})
+}
+
+// handleCORS sets the CORS headers of allowed origins and answers preflight requests
+func handleCORS(e *core.RequestEvent, cfg config.CORSConfig, origins []string) error {
+	origin := e.Request.Header.Get("Origin")
+	preflight := e.Request.Method == http.MethodOptions && e.Request.Header.Get("Access-Control-Request-Method") != ""
+	header := e.Response.Header()
+
+	// Not a cross-origin browser request
+	if origin == "" {
+		if preflight {
+			return e.NoContent(http.StatusNoContent)
+		}
+		return e.Next()
+	}
+
+	policy := corsPolicy(e.Request.URL.Path)
+	methods := corsMethods
+	allowOrigin := ""
+	credentials := false
+
+	switch policy {
+	case CORSOpen:
+		methods = corsOpenMethods
+		allowOrigin = "*"
+	default:
+		header.Add("Vary", "Origin")
+		if allowedOrigin(e, origin, origins, policy == CORSRestricted) {
+			if slices.Contains(origins, "*") && policy == CORSAllowlist {
+				allowOrigin = "*"
+			} else {
+				allowOrigin = origin
+				credentials = cfg.AllowCredentials
+			}
+		}
+	}
+
+	// Browsers block the response without the headers
+	if allowOrigin == "" {
+		if preflight {
+			return e.NoContent(http.StatusNoContent)
+		}
+		return e.Next()
+	}
+
+	header.Set("Access-Control-Allow-Origin", allowOrigin)
+	if credentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+
+	if !preflight {
+		return e.Next()
+	}
+
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+	header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+	if requested := e.Request.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if cfg.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+	}
+
+	return e.NoContent(http.StatusNoContent)
+}
+
+// allowedOrigin reports whether origin is listed or is the origin of APP_URL.
+// exact ignores "*" and the subdomain wildcards of the list.
+func allowedOrigin(e *core.RequestEvent, origin string, origins []string, exact bool) bool {
+	if appURL, err := url.Parse(e.App.Settings().Meta.AppURL); err == nil && appURL.Host != "" {
+		if strings.EqualFold(origin, appURL.Scheme+"://"+appURL.Host) {
+			return true
+		}
+	}
+
+	for _, allowed := range origins {
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+		if !exact && matchOrigin(origin, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin matches "*" and subdomain wildcards like "https://*.example.com"
+func matchOrigin(origin, pattern string) bool {
+	if pattern == "*" {
+		return true
+	}
+
+	scheme, host, ok := strings.Cut(pattern, "://*.")
+	if !ok {
+		return false
+	}
+	originScheme, originHost, ok := strings.Cut(origin, "://")
+	return ok && strings.EqualFold(originScheme, scheme) &&
+		strings.HasSuffix(strings.ToLower(originHost), "."+strings.ToLower(host))
+}
@@ -0,0 +1,129 @@
+// security/cors_test.go
+package security
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+
+	"pocketbase/config"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	cfg := config.CORSConfig{
+		AllowedOrigins:   []string{"https://www.example.com", "https://*.example.com"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+
+	scenarios := []struct {
+		name    string
+		url     string
+		origin  string
+		allowed string
+		methods string
+	}{
+		{
+			name:    "listed origin",
+			url:     "/api/collections/blogs/records",
+			origin:  "https://www.example.com",
+			allowed: "https://www.example.com",
+			methods: "GET, HEAD, PUT, PATCH, POST, DELETE",
+		},
+		{
+			name:    "subdomain wildcard",
+			url:     "/api/collections/blogs/records",
+			origin:  "https://blog.example.com",
+			allowed: "https://blog.example.com",
+			methods: "GET, HEAD, PUT, PATCH, POST, DELETE",
+		},
+		{
+			name:   "disallowed origin",
+			url:    "/api/collections/blogs/records",
+			origin: "https://evil.test",
+		},
+		{
+			name:   "restricted route ignores the wildcard",
+			url:    "/api/collections/users/auth-with-password",
+			origin: "https://blog.example.com",
+		},
+		{
+			name:    "restricted route with a listed origin",
+			url:     "/api/collections/users/auth-with-password",
+			origin:  "https://www.example.com",
+			allowed: "https://www.example.com",
+			methods: "GET, HEAD, PUT, PATCH, POST, DELETE",
+		},
+		{
+			name:    "restricted route with the APP_URL origin",
+			url:     "/api/settings",
+			origin:  "https://app.test",
+			allowed: "https://app.test",
+			methods: "GET, HEAD, PUT, PATCH, POST, DELETE",
+		},
+		{
+			name:    "open route",
+			url:     "/feeds/rss.xml",
+			origin:  "https://evil.test",
+			allowed: "*",
+			methods: "GET, HEAD",
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := tests.ApiScenario{
+			Name:   s.name,
+			Method: http.MethodOptions,
+			URL:    s.url,
+			Headers: map[string]string{
+				"Origin":                         s.origin,
+				"Access-Control-Request-Method":  http.MethodPost,
+				"Access-Control-Request-Headers": "content-type",
+			},
+			ExpectedStatus:  http.StatusNoContent,
+			ExpectedContent: []string{""},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app, err := tests.NewTestApp()
+				if err != nil {
+					t.Fatal(err)
+				}
+				app.Settings().Meta.AppURL = "https://app.test"
+
+				RegisterCORS(app, cfg, false)
+
+				return app
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				header := res.Header
+
+				if got := header.Get("Access-Control-Allow-Origin"); got != s.allowed {
+					t.Fatalf("expected Access-Control-Allow-Origin %q, got %q", s.allowed, got)
+				}
+				if got := header.Get("Access-Control-Allow-Methods"); got != s.methods {
+					t.Fatalf("expected Access-Control-Allow-Methods %q, got %q", s.methods, got)
+				}
+				if s.allowed == "" {
+					return
+				}
+
+				// the wildcard origin never gets credentials
+				wantCredentials := ""
+				if s.allowed != "*" {
+					wantCredentials = "true"
+				}
+				if got := header.Get("Access-Control-Allow-Credentials"); got != wantCredentials {
+					t.Fatalf("expected Access-Control-Allow-Credentials %q, got %q", wantCredentials, got)
+				}
+				if got := header.Get("Access-Control-Allow-Headers"); got != "content-type" {
+					t.Fatalf("expected the requested headers to be allowed, got %q", got)
+				}
+				if got := header.Get("Access-Control-Max-Age"); got != "600" {
+					t.Fatalf("expected Access-Control-Max-Age 600, got %q", got)
+				}
+			},
+		}
+
+		scenario.Test(t)
+	}
+}
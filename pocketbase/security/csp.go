@@ -0,0 +1,125 @@
+// security/csp.go
+package security
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/config"
+)
+
+// ReportPath is the route of the CSP violation collector
+const ReportPath = "/api/csp-report"
+
+// reportGroup is the Reporting API endpoint name of the collector
+const reportGroup = "csp-endpoint"
+
+// apiPolicy is the policy of JSON responses, they never render anything
+const apiPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// policy is a Content-Security-Policy, the sources keyed by directive
+type policy map[string][]string
+
+// directives is the order the directives are written in
+var directives = []string{
+	"default-src", "script-src", "style-src", "font-src", "img-src", "media-src",
+	"connect-src", "frame-src", "frame-ancestors", "base-uri", "form-action", "object-src",
+}
+
+// basePolicy allows the SvelteKit build (with its inline bootstrap script),
+// Google Fonts and images from any HTTPS host
+func basePolicy() policy {
+	return policy{
+		"default-src": {"'self'"},
+		"script-src":  {"'self'", "'unsafe-inline'"},
+		"style-src":   {"'self'", "'unsafe-inline'", "https://fonts.googleapis.com"},
+		"font-src":    {"'self'", "data:", "https://fonts.gstatic.com"},
+		"img-src":     {"'self'", "data:", "blob:", "https:"},
+		"media-src":   {"'self'", "https:"},
+		"connect-src": {"'self'"},
+		"frame-src":   {"'self'"},
+		"base-uri":    {"'self'"},
+		"form-action": {"'self'"},
+		"object-src":  {"'none'"},
+	}
+}
+
+// Hosts of the analytics and ads services the sites record enables
+var (
+	clarityHosts = policy{
+		"script-src":  {"https://www.clarity.ms", "https://*.clarity.ms"},
+		"connect-src": {"https://*.clarity.ms"},
+	}
+	googleTagHosts = policy{
+		"script-src":  {"https://www.googletagmanager.com"},
+		"connect-src": {"https://www.googletagmanager.com", "https://*.google-analytics.com", "https://*.analytics.google.com"},
+	}
+	adSenseHosts = policy{
+		"script-src":  {"https://pagead2.googlesyndication.com", "https://*.googlesyndication.com", "https://*.doubleclick.net", "https://*.google.com", "https://*.adtrafficquality.google"},
+		"connect-src": {"https://*.googlesyndication.com", "https://*.doubleclick.net", "https://*.google.com", "https://*.adtrafficquality.google"},
+		"frame-src":   {"https://*.googlesyndication.com", "https://*.doubleclick.net", "https://*.google.com"},
+	}
+)
+
+// allow adds the sources of other to the policy
+func (p policy) allow(other policy) {
+	for directive, sources := range other {
+		for _, source := range sources {
+			if !slices.Contains(p[directive], source) {
+				p[directive] = append(p[directive], source)
+			}
+		}
+	}
+}
+
+// String renders the policy in the header format
+func (p policy) String() string {
+	parts := make([]string, 0, len(p))
+	for _, directive := range directives {
+		if sources := p[directive]; len(sources) > 0 {
+			parts = append(parts, directive+" "+strings.Join(sources, " "))
+		}
+	}
+	for _, directive := range []string{"report-uri", "report-to"} {
+		if sources := p[directive]; len(sources) > 0 {
+			parts = append(parts, directive+" "+strings.Join(sources, " "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// sitePolicy returns the page policy of a site, allowing the analytics and ads
+// hosts of the services it has ids for. site may be nil.
+func sitePolicy(site *core.Record, cfg config.SecurityConfig) string {
+	p := basePolicy()
+
+	if site != nil {
+		if site.GetString("clarity_tag") != "" {
+			p.allow(clarityHosts)
+		}
+		if site.GetString("google_tag") != "" {
+			p.allow(googleTagHosts)
+		}
+		if site.GetString("google_ads_client") != "" {
+			p.allow(adSenseHosts)
+		}
+	}
+
+	if len(cfg.CSPSources) > 0 {
+		p.allow(policy{
+			"script-src":  cfg.CSPSources,
+			"style-src":   cfg.CSPSources,
+			"img-src":     cfg.CSPSources,
+			"connect-src": cfg.CSPSources,
+			"frame-src":   cfg.CSPSources,
+		})
+	}
+
+	p["frame-ancestors"] = cfg.FrameAncestors
+	p["report-uri"] = []string{ReportPath}
+	p["report-to"] = []string{reportGroup}
+
+	return p.String()
+}
@@ -0,0 +1,137 @@
+// security/headers.go
+//
+// Package security sets the security headers (Content-Security-Policy, HSTS,
+// Referrer-Policy, ...) of static and API responses and collects the CSP
+// violations browsers report.
+package security
+
+import (
+	"log"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/config"
+	"pocketbase/jobs"
+	"pocketbase/sites"
+)
+
+// policies caches the page policy of every site, it's cleared when a sites record changes
+type policies struct {
+	mu     sync.RWMutex
+	bySite map[string]string
+}
+
+func (p *policies) get(e *core.RequestEvent, cfg config.SecurityConfig) string {
+	id := sites.Id(e)
+
+	p.mu.RLock()
+	value, ok := p.bySite[id]
+	p.mu.RUnlock()
+	if ok {
+		return value
+	}
+
+	site, err := sites.Find(e)
+	if err != nil {
+		log.Printf("Warning: failed to load site %s for its CSP: %v", id, err)
+	}
+	value = sitePolicy(site, cfg)
+
+	p.mu.Lock()
+	p.bySite[id] = value
+	p.mu.Unlock()
+
+	return value
+}
+
+func (p *policies) reset() {
+	p.mu.Lock()
+	p.bySite = map[string]string{}
+	p.mu.Unlock()
+}
+
+// Register adds the security headers middleware and the CSP report collector,
+// which stores the reports on the background queue and prunes the old ones.
+// It must be registered after sites.Register, the page policy depends on the site.
+func Register(app core.App, cfg config.SecurityConfig, queue *jobs.Queue) {
+	cache := &policies{bySite: map[string]string{}}
+
+	reset := func(e *core.RecordEvent) error {
+		cache.reset()
+		return e.Next()
+	}
+	app.OnRecordAfterCreateSuccess(sites.Collection).BindFunc(reset)
+	app.OnRecordAfterUpdateSuccess(sites.Collection).BindFunc(reset)
+	app.OnRecordAfterDeleteSuccess(sites.Collection).BindFunc(reset)
+
+	registerPrune(app, cfg.ReportRetention)
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.BindFunc(func(e *core.RequestEvent) error {
+			setHeaders(e, cfg, cache)
+			return e.Next()
+		})
+
+		se.Router.POST(ReportPath, collectReport(queue))
+
+		return se.Next()
+	})
+}
+
+// setHeaders writes the security headers of the request's response
+func setHeaders(e *core.RequestEvent, cfg config.SecurityConfig, cache *policies) {
+	header := e.Response.Header()
+	path := e.Request.URL.Path
+
+	header.Set("X-Content-Type-Options", "nosniff")
+	if cfg.ReferrerPolicy != "" {
+		header.Set("Referrer-Policy", cfg.ReferrerPolicy)
+	}
+
+	if cfg.HSTSMaxAge > 0 && isHTTPS(e) {
+		value := "max-age=" + strconv.Itoa(cfg.HSTSMaxAge)
+		if cfg.HSTSIncludeSubdomains {
+			value += "; includeSubDomains"
+		}
+		header.Set("Strict-Transport-Security", value)
+	}
+
+	// The dashboard ships its own scripts and styles
+	if cfg.CSP == config.CSPOff || strings.HasPrefix(path, "/_/") {
+		return
+	}
+
+	// An enforced frame-ancestors supersedes PocketBase's X-Frame-Options: SAMEORIGIN,
+	// which would still block the additionally allowed ancestors in older browsers.
+	// Report-only policies don't restrict framing, the header stays then.
+	if cfg.CSP == config.CSPEnforce && !slices.Equal(cfg.FrameAncestors, []string{"'self'"}) {
+		header.Del("X-Frame-Options")
+	}
+
+	name := "Content-Security-Policy"
+	if cfg.CSP == config.CSPReportOnly {
+		name = "Content-Security-Policy-Report-Only"
+	}
+
+	if strings.HasPrefix(path, "/api/") {
+		header.Set(name, apiPolicy)
+		return
+	}
+
+	header.Set("Reporting-Endpoints", reportGroup+`="`+ReportPath+`"`)
+	header.Set(name, cache.get(e, cfg))
+}
+
+// isHTTPS reports whether the site is served over HTTPS, directly or behind a TLS terminating proxy
+func isHTTPS(e *core.RequestEvent) bool {
+	if e.IsTLS() {
+		return true
+	}
+	u, err := url.Parse(e.App.Settings().Meta.AppURL)
+	return err == nil && u.Scheme == "https"
+}
@@ -0,0 +1,63 @@
+// security/headers_test.go
+package security
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pocketbase/pocketbase/tests"
+
+	"pocketbase/config"
+	"pocketbase/jobs"
+)
+
+func TestFrameOptions(t *testing.T) {
+	custom := []string{"'self'", "https://embed.example.com"}
+
+	scenarios := []struct {
+		name           string
+		url            string
+		csp            string
+		frameAncestors []string
+		status         int
+		expected       string
+	}{
+		{"enforced custom frame-ancestors", "/api/health", config.CSPEnforce, custom, http.StatusOK, ""},
+		{"enforced default frame-ancestors", "/api/health", config.CSPEnforce, []string{"'self'"}, http.StatusOK, "SAMEORIGIN"},
+		{"report-only custom frame-ancestors", "/api/health", config.CSPReportOnly, custom, http.StatusOK, "SAMEORIGIN"},
+		{"disabled CSP", "/api/health", config.CSPOff, custom, http.StatusOK, "SAMEORIGIN"},
+		// the test app doesn't serve the dashboard, the middleware runs all the same
+		{"dashboard without CSP", "/_/", config.CSPEnforce, custom, http.StatusNotFound, "SAMEORIGIN"},
+	}
+
+	for _, s := range scenarios {
+		scenario := tests.ApiScenario{
+			Name:            s.name,
+			Method:          http.MethodGet,
+			URL:             s.url,
+			ExpectedStatus:  s.status,
+			ExpectedContent: []string{""},
+			TestAppFactory: func(t testing.TB) *tests.TestApp {
+				app, err := tests.NewTestApp()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				cfg := config.SecurityConfig{
+					CSP:            s.csp,
+					FrameAncestors: s.frameAncestors,
+				}
+				Register(app, cfg, jobs.NewQueue(1, 1))
+
+				return app
+			},
+			AfterTestFunc: func(t testing.TB, app *tests.TestApp, res *http.Response) {
+				if got := res.Header.Get("X-Frame-Options"); got != s.expected {
+					t.Fatalf("expected X-Frame-Options %q, got %q", s.expected, got)
+				}
+			},
+		}
+
+		scenario.Test(t)
+	}
+}
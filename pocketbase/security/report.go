@@ -0,0 +1,262 @@
+// security/report.go
+package security
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+
+	"pocketbase/jobs"
+	"pocketbase/proxy"
+	"pocketbase/timestamps"
+)
+
+// ReportsCollection stores the CSP violations
+const ReportsCollection = "csp_reports"
+
+// Collector limits, reports beyond them are accepted but not stored.
+// maxReportsMinute applies to every client IP.
+const (
+	maxReportSize     = 64 * 1024
+	maxReportsMinute  = 60
+	maxReportFieldLen = 2000
+)
+
+// pruneCronId identifies the daily cleanup of old reports
+const pruneCronId = "cspReportsPrune"
+
+// legacyReport is the body of report-uri requests (application/csp-report)
+type legacyReport struct {
+	Report struct {
+		DocumentURI        string `json:"document-uri"`
+		BlockedURI         string `json:"blocked-uri"`
+		ViolatedDirective  string `json:"violated-directive"`
+		EffectiveDirective string `json:"effective-directive"`
+		OriginalPolicy     string `json:"original-policy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"source-file"`
+		LineNumber         int    `json:"line-number"`
+		ScriptSample       string `json:"script-sample"`
+	} `json:"csp-report"`
+}
+
+// reportingAPIReport is an entry of report-to requests (application/reports+json)
+type reportingAPIReport struct {
+	Type      string `json:"type"`
+	UserAgent string `json:"user_agent"`
+	Body      struct {
+		DocumentURL        string `json:"documentURL"`
+		BlockedURL         string `json:"blockedURL"`
+		EffectiveDirective string `json:"effectiveDirective"`
+		OriginalPolicy     string `json:"originalPolicy"`
+		Disposition        string `json:"disposition"`
+		SourceFile         string `json:"sourceFile"`
+		LineNumber         int    `json:"lineNumber"`
+		Sample             string `json:"sample"`
+	} `json:"body"`
+}
+
+// violation is a report in either format
+type violation struct {
+	documentURI        string
+	blockedURI         string
+	effectiveDirective string
+	originalPolicy     string
+	disposition        string
+	sourceFile         string
+	lineNumber         int
+	sample             string
+	userAgent          string
+}
+
+// reportLimiter counts the stored reports of every client IP in the current
+// minute, the counts are dropped when the minute changes
+var reportLimiter = struct {
+	sync.Mutex
+	minute time.Time
+	counts map[string]int
+}{}
+
+// allowReport reports whether the client may store another report this minute
+func allowReport(ip string) bool {
+	reportLimiter.Lock()
+	defer reportLimiter.Unlock()
+
+	minute := time.Now().Truncate(time.Minute)
+	if !minute.Equal(reportLimiter.minute) {
+		reportLimiter.minute = minute
+		reportLimiter.counts = map[string]int{}
+	}
+
+	reportLimiter.counts[ip]++
+	return reportLimiter.counts[ip] <= maxReportsMinute
+}
+
+// registerPrune deletes the reports older than retention days every night
+func registerPrune(app core.App, retention int) {
+	if retention == 0 {
+		return
+	}
+
+	app.Cron().MustAdd(pruneCronId, "0 3 * * *", func() {
+		if err := pruneReports(app, retention); err != nil {
+			log.Printf("Warning: failed to prune the CSP reports: %v", err)
+		}
+	})
+}
+
+// pruneReports deletes the reports created more than retention days ago
+func pruneReports(app core.App, retention int) error {
+	cutoff, err := types.ParseDateTime(time.Now().AddDate(0, 0, -retention))
+	if err != nil {
+		return err
+	}
+
+	_, err = app.DB().Delete(ReportsCollection, dbx.NewExp("[[created]] < {:cutoff}", dbx.Params{"cutoff": cutoff.String()})).Execute()
+	return err
+}
+
+// collectReport returns the handler of report-uri and report-to requests,
+// the violations are stored on the queue after the response was sent
+func collectReport(queue *jobs.Queue) func(e *core.RequestEvent) error {
+	return func(e *core.RequestEvent) error {
+		body, err := io.ReadAll(io.LimitReader(e.Request.Body, maxReportSize))
+		if err != nil {
+			return e.BadRequestError("Failed to read the report.", err)
+		}
+
+		violations, err := parseReport(body, e.Request.UserAgent())
+		if err != nil {
+			return e.BadRequestError("Invalid CSP report.", err)
+		}
+
+		records, err := reportRecords(e.App, violations, proxy.RealIP(e))
+		if err != nil {
+			return err
+		}
+
+		if len(records) > 0 {
+			app := e.App
+			queue.Enqueue("store CSP reports", func(ctx context.Context) error {
+				for _, record := range records {
+					if err := app.SaveWithContext(ctx, record); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		}
+
+		return e.NoContent(http.StatusNoContent)
+	}
+}
+
+// reportRecords returns the records of the violations within the rate limit of the client
+func reportRecords(app core.App, violations []violation, ip string) ([]*core.Record, error) {
+	collection, err := app.FindCachedCollectionByNameOrId(ReportsCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*core.Record
+	for _, v := range violations {
+		if !allowReport(ip) {
+			break
+		}
+
+		record := core.NewRecord(collection)
+		record.Set("document_uri", truncate(v.documentURI))
+		record.Set("blocked_uri", truncate(v.blockedURI))
+		record.Set("effective_directive", truncate(v.effectiveDirective))
+		record.Set("original_policy", truncate(v.originalPolicy))
+		record.Set("disposition", truncate(v.disposition))
+		record.Set("source_file", truncate(v.sourceFile))
+		record.Set("line_number", v.lineNumber)
+		record.Set("sample", truncate(v.sample))
+		record.Set("user_agent", truncate(v.userAgent))
+		timestamps.Fill(record)
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// parseReport reads the legacy single report object or the Reporting API list
+func parseReport(body []byte, userAgent string) ([]violation, error) {
+	trimmed := strings.TrimSpace(string(body))
+
+	if strings.HasPrefix(trimmed, "[") {
+		var reports []reportingAPIReport
+		if err := json.Unmarshal(body, &reports); err != nil {
+			return nil, err
+		}
+
+		violations := make([]violation, 0, len(reports))
+		for _, r := range reports {
+			if r.Type != "csp-violation" {
+				continue
+			}
+			agent := r.UserAgent
+			if agent == "" {
+				agent = userAgent
+			}
+			violations = append(violations, violation{
+				documentURI:        r.Body.DocumentURL,
+				blockedURI:         r.Body.BlockedURL,
+				effectiveDirective: r.Body.EffectiveDirective,
+				originalPolicy:     r.Body.OriginalPolicy,
+				disposition:        r.Body.Disposition,
+				sourceFile:         r.Body.SourceFile,
+				lineNumber:         r.Body.LineNumber,
+				sample:             r.Body.Sample,
+				userAgent:          agent,
+			})
+		}
+		return violations, nil
+	}
+
+	var report legacyReport
+	if err := json.Unmarshal(body, &report); err != nil {
+		return nil, err
+	}
+
+	r := report.Report
+	directive := r.EffectiveDirective
+	if directive == "" {
+		directive = r.ViolatedDirective
+	}
+
+	return []violation{{
+		documentURI:        r.DocumentURI,
+		blockedURI:         r.BlockedURI,
+		effectiveDirective: directive,
+		originalPolicy:     r.OriginalPolicy,
+		disposition:        r.Disposition,
+		sourceFile:         r.SourceFile,
+		lineNumber:         r.LineNumber,
+		sample:             r.ScriptSample,
+		userAgent:          userAgent,
+	}}, nil
+}
+
+// truncate cuts overly long report values to maxReportFieldLen characters
+func truncate(value string) string {
+	n := 0
+	for i := range value {
+		if n == maxReportFieldLen {
+			return value[:i]
+		}
+		n++
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+// seed/command.go
+package seed
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/spf13/cobra"
+
+	"pocketbase/config"
+)
+
+// SchemaFile holds the default records (the default site and tags) as INSERT statements
+const SchemaFile = "schema.sql"
+
+// MustRegister registers the "seed" command and panics if it fails.
+//
+// Example usage:
+//
+//	seed.MustRegister(app, app.RootCmd)
+func MustRegister(app core.App, rootCmd *cobra.Command) {
+	if err := Register(app, rootCmd); err != nil {
+		panic(err)
+	}
+}
+
+// Register registers the "seed" command
+func Register(app core.App, rootCmd *cobra.Command) error {
+	if rootCmd == nil {
+		return fmt.Errorf("missing root command")
+	}
+
+	rootCmd.AddCommand(newSeedCommand(app))
+
+	return nil
+}
+
+func newSeedCommand(app core.App) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "seed [file]...",
+		Short: "Loads fixture records from SQL, JSON or YAML files",
+		Long: `Loads fixture records into the existing collections.
+
+Without arguments the INSERT statements of schema.sql are applied. Records that
+already exist (by id) are left untouched, so seeding the same file twice is safe.
+
+Supported files:
+  .sql          INSERT statements, other statements are ignored
+  .json, .yaml  an object of table names to lists of records`,
+		Example: `  seed
+  seed fixtures/blogs.yaml fixtures/comments.json`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{SchemaFile}
+			}
+
+			for _, path := range args {
+				if err := ApplyFile(app, path); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	command.AddCommand(newDemoCommand(app))
+
+	return command
+}
+
+func newDemoCommand(app core.App) *cobra.Command {
+	opts := DefaultDemoOptions
+
+	command := &cobra.Command{
+		Use:   "demo",
+		Short: "Generates demo users, blogs, projects, comments, likes and feedback",
+		Long: `Generates realistic demo content for local development and screenshots.
+
+The output only depends on --seed, running the command twice with the same seed
+changes nothing. Demo users log in with the password "` + DemoPassword + `".`,
+		Example: `  seed demo
+  seed demo --seed 42 --users 20 --blogs 100 --comments 500`,
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Get()
+			if err != nil {
+				return err
+			}
+			return Demo(app, cfg.AuthCollection, opts)
+		},
+	}
+
+	command.Flags().Int64Var(&opts.Seed, "seed", opts.Seed, "random seed, the same seed generates the same records")
+	command.Flags().IntVar(&opts.Users, "users", opts.Users, "number of users")
+	command.Flags().IntVar(&opts.Tags, "tags", opts.Tags, "number of tags (at most "+strconv.Itoa(len(demoTopics))+")")
+	command.Flags().IntVar(&opts.Blogs, "blogs", opts.Blogs, "number of blogs")
+	command.Flags().IntVar(&opts.Projects, "projects", opts.Projects, "number of projects")
+	command.Flags().IntVar(&opts.Comments, "comments", opts.Comments, "number of comments, about a third are replies")
+	command.Flags().IntVar(&opts.Likes, "likes", opts.Likes, "number of likes")
+	command.Flags().IntVar(&opts.Feedback, "feedback", opts.Feedback, "number of feedback entries")
+
+	return command
+}
@@ -0,0 +1,451 @@
+// seed/demo.go
+package seed
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// DemoPassword is the password of every generated demo user
+const DemoPassword = "demo-password-123"
+
+// demoPasswordHash is a fixed bcrypt hash of DemoPassword. Hashing on every run
+// would use a new random salt, so the generated records would differ between runs.
+const demoPasswordHash = "$2a$10$Ji5ZwhnL3zxKcTok2jfA9eZnxT/Bg.z/RwA.nYfE5p9NMqPKuQaQu"
+
+// DemoOptions configures the amount of generated demo content
+type DemoOptions struct {
+	// Seed makes the output deterministic, the same seed always generates the same records
+	Seed int64
+
+	Users    int
+	Tags     int
+	Blogs    int
+	Projects int
+	Comments int
+	Likes    int
+	Feedback int
+}
+
+// DefaultDemoOptions generates enough content to fill the front page and a few blog posts
+var DefaultDemoOptions = DemoOptions{
+	Seed:     1,
+	Users:    8,
+	Tags:     6,
+	Blogs:    20,
+	Projects: 6,
+	Comments: 60,
+	Likes:    80,
+	Feedback: 12,
+}
+
+var (
+	demoFirstNames = []string{"Ada", "Linus", "Grace", "Ken", "Margaret", "Dennis", "Barbara", "Alan", "Frances", "Edsger", "Radia", "Rob"}
+	demoLastNames  = []string{"Lovelace", "Torvalds", "Hopper", "Thompson", "Hamilton", "Ritchie", "Liskov", "Turing", "Allen", "Dijkstra", "Perlman", "Pike"}
+	demoTopics     = []string{"Go", "Rust", "PocketBase", "SvelteKit", "TypeScript", "DevOps", "Databases", "Design", "Testing", "Performance", "Security", "Open Source"}
+	demoColors     = []string{"#00ADD8", "#DEA584", "#B8DBE4", "#FF3E00", "#3178C6", "#10B981", "#F59E0B", "#EC4899", "#8B5CF6", "#EF4444", "#6366F1", "#14B8A6"}
+	demoTitles     = []string{"Getting started with %s", "%s in production", "What I learned building with %s", "A practical guide to %s", "%s tips and tricks", "Why we moved to %s", "Debugging %s like a pro", "The state of %s"}
+	demoSentences  = []string{
+		"This post walks through the setup step by step.",
+		"Most of the complexity disappears once the data model is right.",
+		"We measured everything before and after the change.",
+		"The official documentation covers the basics well, so this focuses on the details.",
+		"Small, boring building blocks turned out to be the key.",
+		"The first version was far too clever, the second one shipped.",
+		"Everything here runs on a single small server.",
+		"Feedback from readers shaped most of the later sections.",
+	}
+	demoComments = []string{"Great write-up, thanks!", "This saved me hours.", "How does this scale with more users?", "I ran into the same issue last week.", "Could you share the full config?", "Nice, bookmarking this.", "Interesting approach, I did it differently.", "Looking forward to part two!"}
+	demoFeedback = []string{"The dark mode is lovely.", "Search could be faster.", "Code blocks overflow on mobile.", "Love the new layout!", "Comments did not load for me.", "Please add an RSS feed."}
+)
+
+// Demo generates demo users, tags, blogs, projects, comments, likes and feedback
+// and applies them like any other fixture, so running it twice with the same seed is a no-op
+func Demo(app core.App, usersCollection string, opts DemoOptions) error {
+	records, err := GenerateDemo(app, usersCollection, opts)
+	if err != nil {
+		return err
+	}
+
+	return Apply(app, records)
+}
+
+// GenerateDemo returns the demo records in dependency order. Values for fields
+// the collections don't have are left out.
+func GenerateDemo(app core.App, usersCollection string, opts DemoOptions) ([]Record, error) {
+	g := &demoGenerator{
+		app:   app,
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		start: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	users, err := g.collection(usersCollection)
+	if err != nil {
+		return nil, err
+	}
+
+	userIds := g.users(users, opts.Users)
+	if len(userIds) == 0 {
+		return nil, fmt.Errorf("at least one demo user is required")
+	}
+
+	tagIds := g.tags(opts.Tags)
+	blogs := g.blogs(opts.Blogs, userIds, tagIds)
+	g.projects(opts.Projects, userIds)
+	g.comments(opts.Comments, userIds, blogs)
+	g.likes(opts.Likes, userIds, blogs)
+	g.feedback(opts.Feedback, userIds, blogs)
+
+	return g.records, g.err
+}
+
+// demoGenerator collects the generated records, all randomness comes from rng
+type demoGenerator struct {
+	app     core.App
+	rng     *rand.Rand
+	start   time.Time
+	records []Record
+	err     error
+}
+
+// demoBlog is a generated blog the later records refer to
+type demoBlog struct {
+	id      string
+	values  map[string]any
+	slug    string
+	created time.Time
+}
+
+func (g *demoGenerator) collection(name string) (*core.Collection, error) {
+	collection, err := g.app.FindCollectionByNameOrId(name)
+	if err != nil {
+		return nil, fmt.Errorf("collection %s not found, run the migrations first", name)
+	}
+	return collection, nil
+}
+
+// add appends a record with the values of the fields the collection has and
+// returns its values (nil if the collection doesn't exist), a new id is generated
+// unless values has one
+func (g *demoGenerator) add(table string, created time.Time, values map[string]any) map[string]any {
+	collection, err := g.collection(table)
+	if err != nil {
+		if g.err == nil {
+			g.err = err
+		}
+		return nil
+	}
+
+	if _, ok := values["id"]; !ok {
+		values["id"] = g.id()
+	}
+
+	date, _ := types.ParseDateTime(created)
+	values["created"] = date
+	values["updated"] = date
+
+	// schema.sql column names are lowercased by the collection import,
+	// so "passwordHash" may well be the "passwordhash" field
+	fields := map[string]string{}
+	for _, field := range collection.Fields {
+		fields[strings.ToLower(field.GetName())] = field.GetName()
+	}
+
+	for name, value := range values {
+		fieldName, ok := fields[strings.ToLower(name)]
+		if fieldName != name {
+			delete(values, name)
+		}
+		if ok {
+			values[fieldName] = value
+		}
+	}
+
+	g.records = append(g.records, Record{Table: table, Values: values})
+	return values
+}
+
+func (g *demoGenerator) users(collection *core.Collection, count int) []string {
+	var ids []string
+	for i := 0; i < count; i++ {
+		first, last := g.pick(demoFirstNames), g.pick(demoLastNames)
+		id := g.id()
+		username := strings.ToLower(first + "_" + last + "_" + id[:4])
+
+		role := "user"
+		if i < 2 {
+			role = "editor"
+		}
+
+		values := map[string]any{
+			"id":              id,
+			"email":           fmt.Sprintf("%s.%s.%s@example.com", strings.ToLower(first), strings.ToLower(last), id[:4]),
+			"username":        username,
+			"name":            first + " " + last,
+			"role":            role,
+			"bio":             g.pick(demoSentences),
+			"website":         "https://example.com/" + username,
+			"github":          username,
+			"emailVisibility": false,
+			"verified":        true,
+			"tokenKey":        g.id() + g.id(),
+		}
+		if collection.IsAuth() {
+			// set as hash, a plain password would be hashed with a random salt
+			values["password"] = &core.PasswordFieldValue{Hash: demoPasswordHash}
+		} else {
+			// users imported from schema.sql as a plain collection keep the hash themselves
+			values["passwordHash"] = demoPasswordHash
+		}
+
+		if g.add(collection.Name, g.date(), values) == nil {
+			return nil
+		}
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func (g *demoGenerator) tags(count int) []string {
+	if count > len(demoTopics) {
+		count = len(demoTopics)
+	}
+
+	var ids []string
+	for i := 0; i < count; i++ {
+		record := g.add("tags", g.date(), map[string]any{
+			"name":        demoTopics[i],
+			"slug":        slugify(demoTopics[i]),
+			"description": "Posts about " + demoTopics[i],
+			"color":       demoColors[i],
+		})
+		if record != nil {
+			ids = append(ids, record["id"].(string))
+		}
+	}
+
+	return ids
+}
+
+func (g *demoGenerator) blogs(count int, userIds, tagIds []string) []*demoBlog {
+	var blogs []*demoBlog
+	for i := 0; i < count; i++ {
+		topic := g.pick(demoTopics)
+		title := fmt.Sprintf(g.pick(demoTitles), topic)
+		slug := fmt.Sprintf("%s-%d", slugify(title), i+1)
+		created := g.date()
+
+		var tags []string
+		for _, n := range g.rng.Perm(len(tagIds))[:min(len(tagIds), 1+g.rng.Intn(3))] {
+			tags = append(tags, tagIds[n])
+		}
+		encodedTags, _ := json.Marshal(tags)
+
+		record := g.add("blogs", created, map[string]any{
+			"title":          title,
+			"slug":           slug,
+			"summary":        g.pick(demoSentences) + " " + g.pick(demoSentences),
+			"alt":            title,
+			"content_object": g.content(topic),
+			"author":         g.pick(userIds),
+			"tags":           string(encodedTags),
+			"views":          g.rng.Intn(5000),
+			"likes":          0,
+			"published":      g.rng.Intn(10) > 0,
+		})
+		if record == nil {
+			return nil
+		}
+
+		blogs = append(blogs, &demoBlog{id: record["id"].(string), values: record, slug: slug, created: created})
+	}
+
+	return blogs
+}
+
+// content returns a rich text document in the editor's JSON format
+func (g *demoGenerator) content(topic string) string {
+	text := func(s string) []map[string]any {
+		return []map[string]any{{"type": "text", "text": s}}
+	}
+	paragraph := func() map[string]any {
+		return map[string]any{"type": "paragraph", "content": text(g.pick(demoSentences) + " " + g.pick(demoSentences))}
+	}
+
+	var items []map[string]any
+	for i := 0; i < 3; i++ {
+		items = append(items, map[string]any{
+			"type":    "listItem",
+			"content": []map[string]any{{"type": "paragraph", "content": text(g.pick(demoSentences))}},
+		})
+	}
+
+	doc := map[string]any{
+		"type": "doc",
+		"content": []map[string]any{
+			{"type": "heading", "attrs": map[string]any{"level": 2}, "content": text("Why " + topic)},
+			paragraph(),
+			paragraph(),
+			{"type": "bulletList", "content": items},
+			{"type": "heading", "attrs": map[string]any{"level": 2}, "content": text("An example")},
+			{"type": "codeBlock", "attrs": map[string]any{"language": "go"}, "content": text("fmt.Println(\"hello, " + topic + "\")")},
+			{"type": "blockquote", "content": []map[string]any{paragraph()}},
+			paragraph(),
+		},
+	}
+
+	encoded, _ := json.Marshal(doc)
+	return string(encoded)
+}
+
+func (g *demoGenerator) projects(count int, userIds []string) {
+	for i := 0; i < count; i++ {
+		topic := g.pick(demoTopics)
+		name := fmt.Sprintf("%s %s", topic, g.pick([]string{"Toolkit", "Starter", "Dashboard", "CLI", "Playground"}))
+
+		g.add("projects_valiantlynx", g.date(), map[string]any{
+			"name":        name,
+			"tagline":     g.pick(demoSentences),
+			"url":         fmt.Sprintf("https://example.com/projects/%s-%d", slugify(name), i+1),
+			"description": g.pick(demoSentences) + " " + g.pick(demoSentences),
+			"user":        g.pick(userIds),
+			"featured":    i < 2,
+			"active":      g.rng.Intn(5) > 0,
+		})
+	}
+}
+
+// comments creates top level comments and replies, a reply belongs to the blog of its parent
+func (g *demoGenerator) comments(count int, userIds []string, blogs []*demoBlog) {
+	if len(blogs) == 0 {
+		return
+	}
+
+	type demoComment struct {
+		id      string
+		blog    *demoBlog
+		created time.Time
+	}
+
+	var comments []demoComment
+	for i := 0; i < count; i++ {
+		values := map[string]any{
+			"content":  g.pick(demoComments),
+			"author":   g.pick(userIds),
+			"approved": true,
+			"flagged":  g.rng.Intn(20) == 0,
+		}
+
+		blog := blogs[g.rng.Intn(len(blogs))]
+		after := blog.created
+
+		// about a third of the comments are replies
+		if len(comments) > 0 && g.rng.Intn(3) == 0 {
+			parent := comments[g.rng.Intn(len(comments))]
+			blog, after = parent.blog, parent.created
+			values["parent"] = parent.id
+		}
+		values["blog"] = blog.id
+
+		created := g.after(after)
+		record := g.add("comments", created, values)
+		if record == nil {
+			return
+		}
+
+		comments = append(comments, demoComment{id: record["id"].(string), blog: blog, created: created})
+	}
+}
+
+// likes creates unique user/blog pairs and updates the like counters of the blogs
+func (g *demoGenerator) likes(count int, userIds []string, blogs []*demoBlog) {
+	if len(blogs) == 0 {
+		return
+	}
+
+	count = min(count, len(userIds)*len(blogs))
+	seen := map[string]bool{}
+	for len(seen) < count {
+		user, blog := g.pick(userIds), blogs[g.rng.Intn(len(blogs))]
+		key := user + "/" + blog.id
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		g.add("likes", g.after(blog.created), map[string]any{
+			"user": user,
+			"blog": blog.id,
+		})
+
+		if likes, ok := blog.values["likes"].(int); ok {
+			blog.values["likes"] = likes + 1
+		}
+	}
+}
+
+func (g *demoGenerator) feedback(count int, userIds []string, blogs []*demoBlog) {
+	for i := 0; i < count; i++ {
+		values := map[string]any{
+			"emotion": 1 + g.rng.Intn(4),
+			"note":    g.pick(demoFeedback),
+			"status":  g.pick([]string{"new", "reviewed", "resolved"}),
+		}
+		if len(blogs) > 0 {
+			values["url"] = "http://localhost:5173/blogs/" + blogs[g.rng.Intn(len(blogs))].slug
+		}
+		// some feedback is anonymous
+		if g.rng.Intn(3) > 0 {
+			values["user"] = g.pick(userIds)
+		}
+
+		g.add("feedback", g.date(), values)
+	}
+}
+
+func (g *demoGenerator) pick(list []string) string {
+	return list[g.rng.Intn(len(list))]
+}
+
+// id returns a record id in PocketBase's default format
+func (g *demoGenerator) id() string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, 15)
+	for i := range b {
+		b[i] = alphabet[g.rng.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// date returns a time within the first 300 days of the demo year
+func (g *demoGenerator) date() time.Time {
+	return g.start.Add(time.Duration(g.rng.Int63n(int64(300 * 24 * time.Hour))))
+}
+
+// after returns a time within 30 days after t
+func (g *demoGenerator) after(t time.Time) time.Time {
+	return t.Add(time.Duration(1 + g.rng.Int63n(int64(30*24*time.Hour))))
+}
+
+// slugify lowercases s and replaces everything but letters and digits with dashes
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "-")
+}
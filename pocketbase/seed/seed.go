@@ -0,0 +1,184 @@
+// seed/seed.go
+//
+// Package seed loads fixture records into the collections created from schema.sql:
+// the INSERT statements of schema.sql itself and additional SQL, JSON or YAML
+// fixture files for development. Applying the same records twice is a no-op.
+package seed
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+	"gopkg.in/yaml.v3"
+
+	"pocketbase/timestamps"
+)
+
+// Record is a single fixture row
+type Record struct {
+	Table  string
+	Values map[string]any
+}
+
+// seedIdPattern is the id pattern of collections with seeded ids like "default_site"
+const seedIdPattern = `^[a-z0-9_]+$`
+
+// LoadFile reads the records of a fixture file. SQL files (.sql) contribute their
+// INSERT statements, JSON and YAML files (.json, .yaml, .yml) map table names to
+// lists of records:
+//
+//	{"tags": [{"id": "tag_go", "name": "Go", "slug": "go"}]}
+func LoadFile(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".sql":
+		return ParseSQL(string(data))
+	case ".json", ".yaml", ".yml":
+		return parseDocument(data)
+	default:
+		return nil, fmt.Errorf("unsupported fixture file %s, expected .sql, .json, .yaml or .yml", path)
+	}
+}
+
+// parseDocument reads an object of table record lists, YAML being a superset of JSON
+func parseDocument(data []byte) ([]Record, error) {
+	var tables map[string][]map[string]any
+	if err := yaml.Unmarshal(data, &tables); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tables))
+	for name := range tables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var records []Record
+	for _, name := range names {
+		for _, values := range tables[name] {
+			records = append(records, Record{Table: name, Values: values})
+		}
+	}
+
+	return records, nil
+}
+
+// ApplyFile loads and applies the records of a fixture file
+func ApplyFile(app core.App, path string) error {
+	records, err := LoadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	return Apply(app, records)
+}
+
+// Apply creates the records that don't exist yet inside a single transaction.
+//
+// Records keep their given id. Records without one get an id derived from their
+// values, so they are only created once as well. Collections whose id field rejects
+// a given id (such as "default_site") get their id pattern widened to allow underscores.
+func Apply(app core.App, records []Record) error {
+	return app.RunInTransaction(func(txApp core.App) error {
+		created := map[string]int{}
+		existing := map[string]int{}
+		var tables []string
+
+		for _, r := range records {
+			collection, err := txApp.FindCollectionByNameOrId(r.Table)
+			if err != nil {
+				return fmt.Errorf("no collection for table %s", r.Table)
+			}
+
+			if created[r.Table] == 0 && existing[r.Table] == 0 {
+				tables = append(tables, r.Table)
+			}
+
+			id := recordId(r)
+			if _, err := txApp.FindRecordById(collection, id); err == nil {
+				existing[r.Table]++
+				continue
+			}
+
+			if err := allowId(txApp, collection, id); err != nil {
+				return err
+			}
+
+			record := core.NewRecord(collection)
+			record.Id = id
+			for name, value := range r.Values {
+				if collection.Fields.GetByName(name) == nil {
+					return fmt.Errorf("%s has no field %s", r.Table, name)
+				}
+				if password, ok := value.(*core.PasswordFieldValue); ok {
+					// an already hashed password, Set would hash it again
+					record.SetRaw(name, password)
+					continue
+				}
+				record.Set(name, value)
+			}
+			timestamps.Fill(record)
+
+			if err := txApp.Save(record); err != nil {
+				return fmt.Errorf("failed to seed %s/%s: %w", r.Table, id, err)
+			}
+			created[r.Table]++
+		}
+
+		for _, table := range tables {
+			log.Printf("Seeded %s: %d created, %d already present", table, created[table], existing[table])
+		}
+
+		return nil
+	})
+}
+
+// recordId returns the given id or one derived from the record values
+func recordId(r Record) string {
+	if id, ok := r.Values["id"].(string); ok && id != "" {
+		return id
+	}
+
+	// json.Marshal sorts the map keys, so equal records hash equally
+	encoded, _ := json.Marshal(r.Values)
+	sum := sha256.Sum256(append([]byte(r.Table+":"), encoded...))
+
+	return hex.EncodeToString(sum[:])[:15]
+}
+
+// allowId widens the id field of the collection when it rejects id
+func allowId(app core.App, collection *core.Collection, id string) error {
+	field, ok := collection.Fields.GetByName(core.FieldNameId).(*core.TextField)
+	if !ok || field.ValidatePlainValue(id) == nil {
+		return nil
+	}
+
+	field.Pattern = seedIdPattern
+	field.Min = 0
+	if field.Max != 0 && field.Max < len(id) {
+		field.Max = len(id)
+	}
+
+	if err := field.ValidatePlainValue(id); err != nil {
+		return fmt.Errorf("invalid id %q for %s: %w", id, collection.Name, err)
+	}
+
+	if err := app.Save(collection); err != nil {
+		return fmt.Errorf("failed to allow seeded ids in %s: %w", collection.Name, err)
+	}
+
+	log.Printf("Allowing ids matching %s in %s for seeded records", seedIdPattern, collection.Name)
+	return nil
+}
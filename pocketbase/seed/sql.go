@@ -0,0 +1,217 @@
+// seed/sql.go
+package seed
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var insertRegex = regexp.MustCompile(`(?is)^INSERT\s+(?:OR\s+\w+\s+)?INTO\s+(\w+)\s*\(([^)]*)\)\s*VALUES\s*(.+)$`)
+
+// ParseSQL returns the records of every INSERT statement in the SQL script,
+// all other statements are ignored. Values may be strings, numbers, NULL, TRUE and FALSE.
+func ParseSQL(script string) ([]Record, error) {
+	var records []Record
+	for _, statement := range splitStatements(script) {
+		matches := insertRegex.FindStringSubmatch(statement)
+		if matches == nil {
+			continue
+		}
+
+		table := strings.ToLower(matches[1])
+
+		var columns []string
+		for _, column := range strings.Split(matches[2], ",") {
+			columns = append(columns, strings.TrimSpace(column))
+		}
+
+		tuples, err := parseTuples(matches[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid INSERT INTO %s: %w", table, err)
+		}
+
+		for _, tuple := range tuples {
+			if len(tuple) != len(columns) {
+				return nil, fmt.Errorf("invalid INSERT INTO %s: %d columns but %d values", table, len(columns), len(tuple))
+			}
+
+			values := make(map[string]any, len(columns))
+			for i, column := range columns {
+				values[column] = tuple[i]
+			}
+			records = append(records, Record{Table: table, Values: values})
+		}
+	}
+
+	return records, nil
+}
+
+// splitStatements splits the script at semicolons outside of string literals
+// and drops "--" comments
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	inString := false
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+
+		switch {
+		case inString:
+			current.WriteByte(ch)
+			if ch == '\'' {
+				// '' is an escaped quote inside the literal
+				if i+1 < len(script) && script[i+1] == '\'' {
+					current.WriteByte('\'')
+					i++
+				} else {
+					inString = false
+				}
+			}
+		case ch == '\'':
+			inString = true
+			current.WriteByte(ch)
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case ch == ';':
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		default:
+			current.WriteByte(ch)
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// parseTuples parses a comma separated list of parenthesized value tuples
+func parseTuples(input string) ([][]any, error) {
+	p := &tupleParser{input: input}
+
+	var tuples [][]any
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+
+		if len(tuples) > 0 {
+			if !p.consume(',') {
+				return nil, fmt.Errorf("expected ',' at offset %d", p.pos)
+			}
+			p.skipSpace()
+		}
+
+		tuple, err := p.tuple()
+		if err != nil {
+			return nil, err
+		}
+		tuples = append(tuples, tuple)
+	}
+
+	return tuples, nil
+}
+
+// tupleParser reads SQL literal tuples like ('a', 1, NULL)
+type tupleParser struct {
+	input string
+	pos   int
+}
+
+func (p *tupleParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tupleParser) skipSpace() {
+	for !p.done() && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *tupleParser) consume(ch byte) bool {
+	if !p.done() && p.input[p.pos] == ch {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tupleParser) tuple() ([]any, error) {
+	if !p.consume('(') {
+		return nil, fmt.Errorf("expected '(' at offset %d", p.pos)
+	}
+
+	var values []any
+	for {
+		p.skipSpace()
+
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		p.skipSpace()
+		if p.consume(')') {
+			return values, nil
+		}
+		if !p.consume(',') {
+			return nil, fmt.Errorf("expected ',' or ')' at offset %d", p.pos)
+		}
+	}
+}
+
+func (p *tupleParser) value() (any, error) {
+	if p.consume('\'') {
+		var value strings.Builder
+		for !p.done() {
+			ch := p.input[p.pos]
+			p.pos++
+			if ch != '\'' {
+				value.WriteByte(ch)
+				continue
+			}
+			if p.consume('\'') {
+				value.WriteByte('\'')
+				continue
+			}
+			return value.String(), nil
+		}
+		return nil, fmt.Errorf("unterminated string literal")
+	}
+
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(",) \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	literal := p.input[start:p.pos]
+
+	switch strings.ToUpper(literal) {
+	case "":
+		return nil, fmt.Errorf("expected a value at offset %d", start)
+	case "NULL":
+		return nil, nil
+	case "TRUE":
+		return true, nil
+	case "FALSE":
+		return false, nil
+	}
+
+	if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+		return n, nil
+	}
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		return f, nil
+	}
+
+	return nil, fmt.Errorf("unsupported value %q (only literals are supported)", literal)
+}
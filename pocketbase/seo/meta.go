@@ -0,0 +1,93 @@
+// seo/meta.go
+package seo
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Meta is the metadata of a page as seen by crawlers and link unfurlers
+type Meta struct {
+	Title       string
+	Description string
+	Keywords    string
+	Author      string
+	Canonical   string
+	Image       string
+	ImageAlt    string
+
+	// Type is the Open Graph type, "article" or "website"
+	Type string
+
+	SiteName      string
+	TwitterHandle string
+}
+
+// replacedTags matches the tags of the shell that Inject replaces
+var replacedTags = regexp.MustCompile(`(?is)\s*(<title[^>]*>.*?</title>` +
+	`|<meta\s+(name|property)="(description|keywords|author|og:[^"]*|twitter:[^"]*)"[^>]*>` +
+	`|<link\s+rel="canonical"[^>]*>)`)
+
+// headEnd matches the closing head tag
+var headEnd = regexp.MustCompile(`(?i)</head>`)
+
+// Inject replaces the title, description, author, canonical URL and Open Graph/Twitter tags
+// of the HTML document with the ones of meta
+func Inject(document []byte, meta Meta) []byte {
+	end := headEnd.FindIndex(document)
+	if end == nil {
+		return document
+	}
+
+	head := bytes.TrimRight(replacedTags.ReplaceAll(document[:end[0]], nil), " \t\r\n")
+
+	var out bytes.Buffer
+	out.Grow(len(document) + 1024)
+	out.Write(head)
+	out.WriteString(meta.Tags())
+	out.Write(document[end[0]:])
+
+	return out.Bytes()
+}
+
+// Tags renders the HTML tags of meta, empty values are left out
+func (m Meta) Tags() string {
+	var b strings.Builder
+
+	tag := func(format string, value string) {
+		if value != "" {
+			b.WriteString("\n\t\t")
+			b.WriteString(strings.Replace(format, "%s", html.EscapeString(value), 1))
+		}
+	}
+
+	tag(`<title>%s</title>`, m.Title)
+	tag(`<meta name="description" content="%s" />`, m.Description)
+	tag(`<meta name="keywords" content="%s" />`, m.Keywords)
+	tag(`<meta name="author" content="%s" />`, m.Author)
+	tag(`<link rel="canonical" href="%s" />`, m.Canonical)
+
+	tag(`<meta property="og:title" content="%s" />`, m.Title)
+	tag(`<meta property="og:description" content="%s" />`, m.Description)
+	tag(`<meta property="og:type" content="%s" />`, m.Type)
+	tag(`<meta property="og:url" content="%s" />`, m.Canonical)
+	tag(`<meta property="og:image" content="%s" />`, m.Image)
+	tag(`<meta property="og:image:alt" content="%s" />`, m.ImageAlt)
+	tag(`<meta property="og:site_name" content="%s" />`, m.SiteName)
+
+	card := "summary"
+	if m.Image != "" {
+		card = "summary_large_image"
+	}
+	tag(`<meta name="twitter:card" content="%s" />`, card)
+	tag(`<meta name="twitter:site" content="%s" />`, m.TwitterHandle)
+	tag(`<meta name="twitter:title" content="%s" />`, m.Title)
+	tag(`<meta name="twitter:description" content="%s" />`, m.Description)
+	tag(`<meta name="twitter:image" content="%s" />`, m.Image)
+
+	b.WriteString("\n\t")
+
+	return b.String()
+}
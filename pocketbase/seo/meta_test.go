@@ -0,0 +1,39 @@
+// seo/meta_test.go
+package seo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInjectReplacesShellTags(t *testing.T) {
+	shell := `<html><head>
+		<title>Shell</title>
+		<meta name="description" content="shell description" />
+		<meta name="author" content="shell author" />
+		<meta property="og:title" content="Shell" />
+		<link rel="stylesheet" href="/app.css" />
+	</head><body></body></html>`
+
+	out := string(Inject([]byte(shell), Meta{
+		Title:  "Post",
+		Author: "Jane & Co",
+	}))
+
+	for _, expected := range []string{
+		`<title>Post</title>`,
+		`<meta name="author" content="Jane &amp; Co" />`,
+		`<meta property="og:title" content="Post" />`,
+		`<link rel="stylesheet" href="/app.css" />`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %s in\n%s", expected, out)
+		}
+	}
+
+	for _, removed := range []string{"Shell", "shell description", "shell author"} {
+		if strings.Contains(out, removed) {
+			t.Errorf("expected %q to be replaced in\n%s", removed, out)
+		}
+	}
+}
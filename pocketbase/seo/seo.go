@@ -0,0 +1,191 @@
+// seo/seo.go
+//
+// Package seo adds the title, description, canonical URL and Open Graph/Twitter
+// tags of blogs and projects to the SPA shell, so crawlers and link unfurlers
+// see more than the generic index.html.
+package seo
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/sites"
+)
+
+// descriptionLength is the maximum length of a description, longer ones are cut at a word
+const descriptionLength = 200
+
+// page looks up the metadata of a route, ok is false when it doesn't have a record
+type page func(e *core.RequestEvent, param string) (meta Meta, ok bool, err error)
+
+// pages are the routes with metadata, keyed by their path prefix
+var pages = []struct {
+	prefix string
+	lookup page
+}{
+	{"/blogs/", blogMeta},
+	{"/projects/", projectMeta},
+}
+
+// Render is a static.ShellRenderer that injects the metadata of /blogs/{slug}
+// and /projects/{id} into the shell. Other routes and unknown records get the shell as is.
+func Render(e *core.RequestEvent, shell []byte) ([]byte, error) {
+	for _, p := range pages {
+		param, ok := strings.CutPrefix(e.Request.URL.Path, p.prefix)
+		if !ok || param == "" || strings.Contains(param, "/") {
+			continue
+		}
+
+		meta, ok, err := p.lookup(e, param)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		site, err := sites.Find(e)
+		if err != nil {
+			return nil, err
+		}
+
+		base := sites.URL(e)
+		meta.Canonical = base + e.Request.URL.EscapedPath()
+		meta.Image = sites.AbsoluteURL(base, meta.Image)
+		if site != nil {
+			meta.SiteName = site.GetString("site_name")
+			meta.TwitterHandle = twitterHandle(site.GetString("twitter_handle"))
+			if meta.Image == "" {
+				meta.Image = sites.AbsoluteURL(base, site.GetString("og_image"))
+			}
+			if meta.Keywords == "" {
+				meta.Keywords = site.GetString("meta_keywords")
+			}
+			if meta.Author == "" {
+				meta.Author = meta.SiteName
+			}
+		}
+		if meta.SiteName != "" {
+			meta.Title += " | " + meta.SiteName
+		}
+
+		return Inject(shell, meta), nil
+	}
+
+	return nil, nil
+}
+
+func blogMeta(e *core.RequestEvent, slug string) (Meta, bool, error) {
+	filter, params := sites.Scope(e, "slug = {:slug} && published = true", dbx.Params{"slug": slug})
+	blog, err := e.App.FindFirstRecordByFilter("blogs", filter, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Meta{}, false, nil
+		}
+		return Meta{}, false, err
+	}
+
+	return Meta{
+		Title:       blog.GetString("title"),
+		Description: truncate(blog.GetString("summary")),
+		Keywords:    tagNames(e.App, blog.GetString("tags")),
+		Author:      authorName(e.App, blog),
+		Image:       blog.GetString("image"),
+		ImageAlt:    blog.GetString("alt"),
+		Type:        "article",
+	}, true, nil
+}
+
+func projectMeta(e *core.RequestEvent, id string) (Meta, bool, error) {
+	filter, params := sites.Scope(e, "id = {:id}", dbx.Params{"id": id})
+	project, err := e.App.FindFirstRecordByFilter("projects_valiantlynx", filter, params)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Meta{}, false, nil
+		}
+		return Meta{}, false, err
+	}
+
+	description := project.GetString("tagline")
+	if description == "" {
+		description = project.GetString("description")
+	}
+
+	return Meta{
+		Title:       project.GetString("name"),
+		Description: truncate(description),
+		Image:       project.GetString("thumbnail"),
+		ImageAlt:    project.GetString("name"),
+		Type:        "website",
+	}, true, nil
+}
+
+// tagNames returns the comma separated names of the tag ids in the blog's tags JSON list
+func tagNames(app core.App, tags string) string {
+	var ids []string
+	for _, id := range strings.Split(strings.Trim(tags, "[] "), ",") {
+		if id = strings.Trim(strings.TrimSpace(id), `"`); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return ""
+	}
+
+	records, err := app.FindRecordsByIds("tags", ids)
+	if err != nil {
+		return ""
+	}
+
+	names := make([]string, 0, len(records))
+	for _, record := range records {
+		names = append(names, record.GetString("name"))
+	}
+	return strings.Join(names, ", ")
+}
+
+// authorName returns the name or username of the blog's author, empty when unknown
+func authorName(app core.App, blog *core.Record) string {
+	field, ok := blog.Collection().Fields.GetByName("author").(*core.RelationField)
+	if !ok || blog.GetString("author") == "" {
+		return ""
+	}
+
+	author, err := app.FindRecordById(field.CollectionId, blog.GetString("author"))
+	if err != nil {
+		return ""
+	}
+
+	if name := author.GetString("name"); name != "" {
+		return name
+	}
+	return author.GetString("username")
+}
+
+// twitterHandle returns the handle with its leading @, also when a profile URL is stored
+func twitterHandle(handle string) string {
+	handle = strings.TrimSpace(handle)
+	if handle == "" {
+		return ""
+	}
+	if i := strings.LastIndex(handle, "/"); i >= 0 {
+		handle = handle[i+1:]
+	}
+	return "@" + strings.TrimPrefix(handle, "@")
+}
+
+// truncate shortens the text to descriptionLength at a word boundary
+func truncate(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= descriptionLength {
+		return text
+	}
+
+	cut := string([]rune(text)[:descriptionLength])
+	if i := strings.LastIndex(cut, " "); i > descriptionLength/2 {
+		cut = cut[:i]
+	}
+	return cut + "…"
+}
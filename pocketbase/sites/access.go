@@ -0,0 +1,100 @@
+// sites/access.go
+package sites
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/pocketbase/pocketbase/apis"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Header carries the site of the request to the list and view rules of the scoped
+// collections (@request.headers.x_site_id, see migration 1750250000). The site
+// middleware overwrites whatever the client sent.
+const Header = "X-Site-Id"
+
+// Allows reports whether the record belongs to the site of the request,
+// records without site belong to the default site
+func Allows(e *core.RequestEvent, record *core.Record) bool {
+	return allows(Id(e), record.GetString("site"))
+}
+
+func allows(id string, site string) bool {
+	return site == id || (site == "" && id == DefaultId)
+}
+
+// registerAccess keeps the requests of the scoped collections within the site of
+// the request. Lists are limited by the API rules, the hooks cover single records,
+// writes (batch requests included) and realtime messages. Superusers manage every site.
+func registerAccess(app core.App) {
+	app.OnRecordViewRequest(Scoped...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if !e.HasSuperuserAuth() && !Allows(e.RequestEvent, e.Record) {
+			return e.NotFoundError("", nil)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordCreateRequest(Scoped...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if e.Record.GetString("site") == "" {
+			e.Record.Set("site", Id(e.RequestEvent))
+		}
+		if !e.HasSuperuserAuth() && e.Record.GetString("site") != Id(e.RequestEvent) {
+			return e.ForbiddenError("Records can only be created for the site of the request.", nil)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordUpdateRequest(Scoped...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if e.HasSuperuserAuth() {
+			return e.Next()
+		}
+		if !allows(Id(e.RequestEvent), e.Record.Original().GetString("site")) {
+			return e.NotFoundError("", nil)
+		}
+		if !Allows(e.RequestEvent, e.Record) {
+			return e.ForbiddenError("Records can't be moved to another site.", nil)
+		}
+		return e.Next()
+	})
+
+	app.OnRecordDeleteRequest(Scoped...).BindFunc(func(e *core.RecordRequestEvent) error {
+		if !e.HasSuperuserAuth() && !Allows(e.RequestEvent, e.Record) {
+			return e.NotFoundError("", nil)
+		}
+		return e.Next()
+	})
+
+	// The realtime rules are checked with the headers of the subscription options,
+	// which the client chooses, so the messages are filtered by the connection's site
+	app.OnRealtimeMessageSend().BindFunc(func(e *core.RealtimeMessageEvent) error {
+		if e.RequestEvent == nil || realtimeSuperuser(e) {
+			return e.Next()
+		}
+
+		var message struct {
+			Record struct {
+				CollectionName string `json:"collectionName"`
+				Site           string `json:"site"`
+			} `json:"record"`
+		}
+		if json.Unmarshal(e.Message.Data, &message) != nil {
+			return e.Next()
+		}
+
+		record := message.Record
+		if slices.Contains(Scoped, record.CollectionName) && !allows(Id(e.RequestEvent), record.Site) {
+			// not sent
+			return nil
+		}
+
+		return e.Next()
+	})
+}
+
+// realtimeSuperuser reports whether the realtime client is authenticated as superuser,
+// the client may have changed its auth since it connected
+func realtimeSuperuser(e *core.RealtimeMessageEvent) bool {
+	auth, _ := e.Client.Get(apis.RealtimeClientAuthKey).(*core.Record)
+	return auth != nil && auth.IsSuperuser()
+}
@@ -0,0 +1,137 @@
+// sites/config.go
+package sites
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/pocketbase/pocketbase/core"
+
+	"pocketbase/static"
+)
+
+// ConfigPath is the route of the public site configuration
+const ConfigPath = "/api/site"
+
+// configCacheControl lets browsers and CDNs reuse the configuration for a few minutes,
+// stale copies are revalidated by their ETag in the background
+const configCacheControl = "public, max-age=300, stale-while-revalidate=3600"
+
+// Config is the public configuration of a site, read by the static build
+// and any other frontend instead of the sites collection
+type Config struct {
+	Id            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	URL           string          `json:"url"`
+	Logo          string          `json:"logo"`
+	Favicon       string          `json:"favicon"`
+	Keywords      string          `json:"keywords"`
+	OGImage       string          `json:"ogImage"`
+	TwitterHandle string          `json:"twitterHandle"`
+	Social        SocialConfig    `json:"social"`
+	Analytics     AnalyticsConfig `json:"analytics"`
+}
+
+// SocialConfig are the profile links of a site
+type SocialConfig struct {
+	Facebook string `json:"facebook"`
+	Github   string `json:"github"`
+	Linkedin string `json:"linkedin"`
+}
+
+// AnalyticsConfig are the tracking and ads ids of a site, empty when not used
+type AnalyticsConfig struct {
+	ClarityTag      string `json:"clarityTag"`
+	GoogleTag       string `json:"googleTag"`
+	GoogleAdsClient string `json:"googleAdsClient"`
+}
+
+// NewConfig returns the public configuration of the site record, base is the site URL
+func NewConfig(site *core.Record, base string) Config {
+	return Config{
+		Id:            site.Id,
+		Name:          site.GetString("site_name"),
+		Description:   site.GetString("site_description"),
+		URL:           base,
+		Logo:          AbsoluteURL(base, site.GetString("site_logo")),
+		Favicon:       AbsoluteURL(base, site.GetString("site_favicon")),
+		Keywords:      site.GetString("meta_keywords"),
+		OGImage:       AbsoluteURL(base, site.GetString("og_image")),
+		TwitterHandle: site.GetString("twitter_handle"),
+		Social: SocialConfig{
+			Facebook: site.GetString("facebook_url"),
+			Github:   site.GetString("github_url"),
+			Linkedin: site.GetString("linkedin_url"),
+		},
+		Analytics: AnalyticsConfig{
+			ClarityTag:      site.GetString("clarity_tag"),
+			GoogleTag:       site.GetString("google_tag"),
+			GoogleAdsClient: site.GetString("google_ads_client"),
+		},
+	}
+}
+
+// serveConfig serves the configuration of the requested site
+func serveConfig(e *core.RequestEvent) error {
+	site, err := Find(e)
+	if err != nil {
+		return err
+	}
+	if site == nil {
+		return e.NotFoundError("The site isn't configured.", nil)
+	}
+
+	body, err := json.Marshal(NewConfig(site, URL(e)))
+	if err != nil {
+		return err
+	}
+
+	e.Response.Header().Set("Cache-Control", configCacheControl)
+
+	return static.ServeContent(e, "application/json", site.GetDateTime("updated").Time(), body)
+}
+
+// serveAdsTxt serves ads.txt with the AdSense publisher of the requested site,
+// sites without google_ads_client don't have one
+func serveAdsTxt(e *core.RequestEvent) error {
+	site, err := Find(e)
+	if err != nil {
+		return err
+	}
+
+	line := AdsTxt(site)
+	if line == "" {
+		return e.String(http.StatusNotFound, "ads.txt isn't configured for this site\n")
+	}
+
+	e.Response.Header().Set("Cache-Control", configCacheControl)
+
+	return static.ServeContent(e, "text/plain; charset=utf-8", site.GetDateTime("updated").Time(), []byte(line))
+}
+
+// AdsTxt returns the ads.txt record of the site's AdSense client id ("ca-pub-..."),
+// empty when there is none
+func AdsTxt(site *core.Record) string {
+	if site == nil {
+		return ""
+	}
+
+	client := strings.TrimSpace(site.GetString("google_ads_client"))
+	publisher := strings.TrimPrefix(client, "ca-")
+	if !strings.HasPrefix(publisher, "pub-") {
+		return ""
+	}
+
+	// f08c47fec0942fa0 is Google's certification authority id
+	return "google.com, " + publisher + ", DIRECT, f08c47fec0942fa0\n"
+}
+
+// AbsoluteURL resolves a relative path (e.g. of an image) against the site URL base
+func AbsoluteURL(base, ref string) string {
+	if ref == "" || strings.Contains(ref, "://") {
+		return ref
+	}
+	return base + "/" + strings.TrimLeft(ref, "/")
+}
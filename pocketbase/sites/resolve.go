@@ -0,0 +1,131 @@
+// sites/resolve.go
+package sites
+
+import (
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// domains maps the hosts of the domain lists to their site id, it's
+// rebuilt on the first request after a sites record changed
+type domains struct {
+	mu    sync.RWMutex
+	hosts map[string]string
+
+	// generation changes on every reset, a rebuild that started
+	// before a reset doesn't replace the newer state
+	generation uint64
+}
+
+func (d *domains) reset() {
+	d.mu.Lock()
+	d.hosts = nil
+	d.generation++
+	d.mu.Unlock()
+}
+
+func (d *domains) lookup(app core.App, host string) (string, bool) {
+	d.mu.RLock()
+	hosts, generation := d.hosts, d.generation
+	d.mu.RUnlock()
+
+	if hosts == nil {
+		hosts = map[string]string{}
+
+		records, err := app.FindAllRecords(Collection)
+		if err != nil {
+			log.Printf("Warning: failed to load the site domains: %v", err)
+			return "", false
+		}
+		for _, record := range records {
+			for _, domain := range ParseDomains(record.GetString("domain")) {
+				hosts[domain] = record.Id
+			}
+		}
+
+		d.mu.Lock()
+		if d.generation == generation {
+			d.hosts = hosts
+		}
+		d.mu.Unlock()
+	}
+
+	host = normalizeHost(host)
+	if id, ok := hosts[host]; ok {
+		return id, true
+	}
+	id, ok := hosts[strings.TrimPrefix(host, "www.")]
+	return id, ok
+}
+
+// ParseDomains splits a domain list (separated by commas, spaces or new lines) into
+// hosts. Entries may be written as URLs, only their host is kept.
+func ParseDomains(list string) []string {
+	var hosts []string
+	for _, entry := range strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}) {
+		if strings.Contains(entry, "://") {
+			if u, err := url.Parse(entry); err == nil {
+				entry = u.Host
+			}
+		}
+		if host := normalizeHost(strings.TrimSuffix(entry, "/")); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// normalizeHost lowercases the host and strips the port
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// Register resolves the site of every request by its host, keeps the record requests
+// of the scoped collections within that site and assigns new records to it.
+// It also serves ads.txt and the public site configuration.
+func Register(app core.App) {
+	index := &domains{}
+
+	app.OnRecordAfterCreateSuccess(Collection).BindFunc(func(e *core.RecordEvent) error {
+		index.reset()
+		return e.Next()
+	})
+	app.OnRecordAfterUpdateSuccess(Collection).BindFunc(func(e *core.RecordEvent) error {
+		index.reset()
+		return e.Next()
+	})
+	app.OnRecordAfterDeleteSuccess(Collection).BindFunc(func(e *core.RecordEvent) error {
+		index.reset()
+		return e.Next()
+	})
+
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.BindFunc(func(e *core.RequestEvent) error {
+			r := resolution{id: DefaultId}
+			if id, ok := index.lookup(e.App, e.Request.Host); ok {
+				r = resolution{id: id, byDomain: true}
+			}
+			e.Set(requestKey, r)
+			e.Request.Header.Set(Header, r.id)
+
+			return e.Next()
+		})
+
+		se.Router.GET("/ads.txt", serveAdsTxt)
+		se.Router.GET(ConfigPath, serveConfig)
+
+		return se.Next()
+	})
+
+	registerAccess(app)
+}
@@ -0,0 +1,110 @@
+// sites/sites.go
+//
+// Package sites resolves the site a request belongs to by its host, so one
+// PocketBase instance can serve several blogs. Blogs, projects and tags belong
+// to a site through their site relation, records without one to the default site.
+package sites
+
+import (
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/pocketbase/dbx"
+	"github.com/pocketbase/pocketbase/core"
+)
+
+// Collection is the name of the sites collection
+const Collection = "sites"
+
+// DefaultId is the site seeded from schema.sql, it serves every host
+// that isn't listed in the domain of another site
+const DefaultId = "default_site"
+
+// Scoped are the collections whose records belong to a site
+var Scoped = []string{"blogs", "projects_valiantlynx", "tags"}
+
+// requestKey stores the resolution of the request in the request event
+const requestKey = "site"
+
+// resolution is the site of a request
+type resolution struct {
+	id string
+
+	// byDomain is true when the host is listed in the site's domain
+	byDomain bool
+}
+
+// Id returns the id of the site the request resolved to
+func Id(e *core.RequestEvent) string {
+	return resolved(e).id
+}
+
+func resolved(e *core.RequestEvent) resolution {
+	if r, ok := e.Get(requestKey).(resolution); ok {
+		return r
+	}
+	return resolution{id: DefaultId}
+}
+
+// Find returns the site of the request, nil when the record doesn't exist
+func Find(e *core.RequestEvent) (*core.Record, error) {
+	site, err := e.App.FindRecordById(Collection, Id(e))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return site, err
+}
+
+// Filter returns the record filter that limits a scoped collection to the
+// site of the request, records without site belong to the default site
+func Filter(e *core.RequestEvent) (string, dbx.Params) {
+	id := Id(e)
+	if id == DefaultId {
+		return "(site = {:site} || site = '')", dbx.Params{"site": id}
+	}
+	return "site = {:site}", dbx.Params{"site": id}
+}
+
+// Scope combines filter with the site filter of the request
+func Scope(e *core.RequestEvent, filter string, params dbx.Params) (string, dbx.Params) {
+	siteFilter, siteParams := Filter(e)
+
+	combined := dbx.Params{}
+	for key, value := range params {
+		combined[key] = value
+	}
+	for key, value := range siteParams {
+		combined[key] = value
+	}
+
+	if filter == "" {
+		return siteFilter, combined
+	}
+	return "(" + filter + ") && " + siteFilter, combined
+}
+
+// URL returns the public URL of the site without trailing slash. Hosts listed in
+// the site's domain are used as requested, otherwise APP_URL when it's set.
+func URL(e *core.RequestEvent) string {
+	appURL := strings.TrimRight(e.App.Settings().Meta.AppURL, "/")
+
+	scheme := "http"
+	if e.IsTLS() {
+		scheme = "https"
+	}
+
+	if resolved(e).byDomain {
+		// TLS usually ends at the proxy, the scheme of APP_URL applies to every site
+		if u, err := url.Parse(appURL); err == nil && u.Scheme != "" {
+			scheme = u.Scheme
+		}
+		return scheme + "://" + e.Request.Host
+	}
+
+	if appURL != "" {
+		return appURL
+	}
+	return scheme + "://" + e.Request.Host
+}
@@ -0,0 +1,91 @@
+// static/cache.go
+package static
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Cache-Control values
+const (
+	// CacheImmutable is for fingerprinted files, their name changes with their content
+	CacheImmutable = "public, max-age=31536000, immutable"
+
+	// CacheRevalidate makes clients and proxies check the ETag before every reuse,
+	// so a deploy is picked up immediately
+	CacheRevalidate = "no-cache"
+
+	// CacheShort is for unversioned assets like favicon.ico or robots.txt
+	CacheShort = "public, max-age=3600"
+)
+
+// CacheRule sets the Cache-Control of the files below Prefix or with extension Ext
+type CacheRule struct {
+	Prefix string
+	Ext    string
+	Value  string
+}
+
+// CacheRules are checked in order, the first match wins and CacheShort applies to the rest
+var CacheRules = []CacheRule{
+	// SvelteKit puts the hashed JS, CSS and fonts below _app/immutable
+	{Prefix: "_app/immutable/", Value: CacheImmutable},
+	// HTML references the current asset hashes and must never go stale
+	{Ext: ".html", Value: CacheRevalidate},
+	// _app/version.json is polled to detect new deploys
+	{Ext: ".json", Value: CacheRevalidate},
+	{Ext: ".webmanifest", Value: CacheRevalidate},
+}
+
+// CacheControl returns the Cache-Control header for the file name
+func CacheControl(name string) string {
+	for _, rule := range CacheRules {
+		if rule.Prefix != "" && !strings.HasPrefix(name, rule.Prefix) {
+			continue
+		}
+		if rule.Ext != "" && path.Ext(name) != rule.Ext {
+			continue
+		}
+		return rule.Value
+	}
+	return CacheShort
+}
+
+// etags caches the content hashes by file name, size and modification time
+var etags sync.Map
+
+// fileETag returns a strong ETag of the file content, every encoded variant
+// has its own. The content is rewound after hashing.
+func fileETag(name string, info fs.FileInfo, content io.ReadSeeker) (string, error) {
+	key := fmt.Sprintf("%s|%d|%d", name, info.Size(), info.ModTime().UnixNano())
+	if etag, ok := etags.Load(key); ok {
+		return etag.(string), nil
+	}
+
+	etag, err := contentETag(content)
+	if err != nil {
+		return "", err
+	}
+	if _, err := content.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	etags.Store(key, etag)
+
+	return etag, nil
+}
+
+// contentETag returns a strong ETag of the content
+func contentETag(content io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, content); err != nil {
+		return "", err
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`, nil
+}
@@ -0,0 +1,39 @@
+// static/source.go
+package static
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// DefaultDir is the directory the site is served from when it isn't embedded
+const DefaultDir = "pb_public"
+
+// Source picks the files to serve: dir when set (PB_PUBLIC_DIR, for hot-reload
+// during development), then the embedded site, then pb_public in the working
+// directory and finally pb_public next to the executable. The returned
+// description is meant for logging.
+func Source(dir string, embedded fs.FS) (fs.FS, string) {
+	if dir != "" {
+		return os.DirFS(dir), dir
+	}
+
+	if embedded != nil {
+		return embedded, "embedded files"
+	}
+
+	if info, err := os.Stat(DefaultDir); err == nil && info.IsDir() {
+		return os.DirFS(DefaultDir), DefaultDir
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		nextToBinary := filepath.Join(filepath.Dir(executable), DefaultDir)
+		if info, err := os.Stat(nextToBinary); err == nil && info.IsDir() {
+			return os.DirFS(nextToBinary), nextToBinary
+		}
+	}
+
+	// Nothing to serve yet, requests fail with 404 until the directory exists
+	return os.DirFS(DefaultDir), DefaultDir
+}
@@ -0,0 +1,263 @@
+// static/static.go
+//
+// Package static serves the built SvelteKit site from pb_public for every
+// non-API route, with cache headers, ETags and precompressed variants.
+package static
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/router"
+)
+
+// IndexFile is the file served for directories
+const IndexFile = "index.html"
+
+// NotFoundFile is the page served with a 404 status for missing files
+const NotFoundFile = "404.html"
+
+// FallbackFiles are the SPA shells served for client side routes, the first
+// existing one wins (SvelteKit's static adapter writes the fallback page it's configured with)
+var FallbackFiles = []string{"200.html", IndexFile}
+
+// encodings are the precompressed variants in order of preference
+var encodings = []struct {
+	name string
+	ext  string
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// ShellRenderer rewrites the SPA shell served for a client side route, e.g. to add
+// the meta tags of the requested page. It returns nil to serve the shell unchanged.
+type ShellRenderer func(e *core.RequestEvent, shell []byte) ([]byte, error)
+
+// Register serves the files of fsys as catch-all route, so every route that is
+// registered by PocketBase or our own packages takes precedence.
+// render is optional and applied to the SPA shell.
+func Register(app core.App, fsys fs.FS, render ShellRenderer) {
+	app.OnServe().BindFunc(func(se *core.ServeEvent) error {
+		se.Router.GET("/{path...}", func(e *core.RequestEvent) error {
+			urlPath := e.Request.URL.Path
+
+			// Unknown API and admin routes get PocketBase's JSON 404
+			if strings.HasPrefix(urlPath, "/api/") ||
+				strings.HasPrefix(urlPath, "/_/") ||
+				strings.HasPrefix(urlPath, "/admin") {
+				return e.NotFoundError("", nil)
+			}
+
+			name := strings.TrimPrefix(path.Clean(urlPath), "/")
+
+			if file, ok := lookup(fsys, name); ok {
+				return ServeFile(e, fsys, file)
+			}
+
+			// Client side routes get the SPA shell, missing assets a real 404
+			if path.Ext(name) == "" {
+				e.Response.Header().Add("Vary", "Accept")
+
+				if isNavigation(e.Request) {
+					for _, fallback := range FallbackFiles {
+						if exists(fsys, fallback) {
+							return serveShell(e, fsys, fallback, render)
+						}
+					}
+				}
+			}
+
+			return NotFound(e, fsys)
+		})
+
+		return se.Next()
+	})
+}
+
+// lookup maps a request path to a file of fsys. Directories are served by their
+// index.html and paths without extension by a prerendered name.html.
+func lookup(fsys fs.FS, name string) (string, bool) {
+	if name == "" || name == "." {
+		name = IndexFile
+	}
+
+	info, err := fs.Stat(fsys, name)
+	switch {
+	case err == nil && !info.IsDir():
+		return name, true
+	case err == nil && info.IsDir():
+		index := path.Join(name, IndexFile)
+		return index, exists(fsys, index)
+	case path.Ext(name) == "" && exists(fsys, name+".html"):
+		return name + ".html", true
+	}
+
+	return "", false
+}
+
+// exists reports whether name is a regular file of fsys
+func exists(fsys fs.FS, name string) bool {
+	info, err := fs.Stat(fsys, name)
+	return err == nil && !info.IsDir()
+}
+
+// isNavigation reports whether the request is a browser or crawler loading a page,
+// as opposed to a script, stylesheet or image request
+func isNavigation(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	return r.Header.Get("Sec-Fetch-Mode") == "navigate" ||
+		strings.Contains(r.Header.Get("Accept"), "text/html")
+}
+
+// NotFound responds with status 404 and the 404.html page of fsys, or plain text without one
+func NotFound(e *core.RequestEvent, fsys fs.FS) error {
+	header := e.Response.Header()
+	header.Set("Cache-Control", CacheRevalidate)
+
+	page, err := fs.ReadFile(fsys, NotFoundFile)
+	if err != nil {
+		return e.String(http.StatusNotFound, "404 page not found\n")
+	}
+
+	if e.Request.Method == http.MethodHead {
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		return e.NoContent(http.StatusNotFound)
+	}
+
+	return e.HTML(http.StatusNotFound, string(page))
+}
+
+// serveShell writes the SPA shell, rendered for the requested route when possible
+func serveShell(e *core.RequestEvent, fsys fs.FS, name string, render ShellRenderer) error {
+	if render == nil {
+		return ServeFile(e, fsys, name)
+	}
+
+	shell, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return err
+	}
+
+	html, err := render(e, shell)
+	if err != nil {
+		return err
+	}
+	if html == nil {
+		return ServeFile(e, fsys, name)
+	}
+
+	return ServeHTML(e, html)
+}
+
+// ServeHTML writes a generated page, it's revalidated by its ETag on every use
+func ServeHTML(e *core.RequestEvent, html []byte) error {
+	return ServeContent(e, "text/html; charset=utf-8", time.Time{}, html)
+}
+
+// ServeContent writes generated content (pages, feeds, sitemaps) with an ETag and,
+// unless modified is zero, Last-Modified, so it's revalidated by conditional requests.
+// A Cache-Control header set before is kept, it's CacheRevalidate otherwise.
+func ServeContent(e *core.RequestEvent, contentType string, modified time.Time, content []byte) error {
+	etag, err := contentETag(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+
+	header := e.Response.Header()
+	header.Set("Content-Type", contentType)
+	header.Set("ETag", etag)
+	if header.Get("Cache-Control") == "" {
+		header.Set("Cache-Control", CacheRevalidate)
+	}
+
+	http.ServeContent(e.Response, e.Request, "", modified, bytes.NewReader(content))
+
+	return nil
+}
+
+// ServeFile writes the file name of fsys with its cache headers. A precompressed
+// name.br or name.gz is served instead when the client accepts it.
+// Conditional and range requests are handled by http.ServeContent.
+func ServeFile(e *core.RequestEvent, fsys fs.FS, name string) error {
+	header := e.Response.Header()
+
+	served := name
+	for _, encoding := range encodings {
+		if !acceptsEncoding(e.Request.Header.Get("Accept-Encoding"), encoding.name) {
+			continue
+		}
+		if info, err := fs.Stat(fsys, name+encoding.ext); err == nil && !info.IsDir() {
+			served = name + encoding.ext
+			header.Set("Content-Encoding", encoding.name)
+			break
+		}
+	}
+
+	f, err := fsys.Open(served)
+	if err != nil {
+		return router.ErrFileNotFound
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		return errors.New("static file does not implement io.ReadSeeker")
+	}
+
+	etag, err := fileETag(served, info, content)
+	if err != nil {
+		return err
+	}
+
+	// The type of the original file, http.ServeContent would sniff the compressed bytes
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	header.Set("Content-Type", contentType)
+	header.Set("Cache-Control", CacheControl(name))
+	header.Set("ETag", etag)
+	header.Add("Vary", "Accept-Encoding")
+
+	http.ServeContent(e.Response, e.Request, name, info.ModTime(), content)
+
+	return nil
+}
+
+// acceptsEncoding reports whether the Accept-Encoding header allows the encoding
+func acceptsEncoding(acceptEncoding, encoding string) bool {
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, encoding) && name != "*" {
+			continue
+		}
+
+		// "br;q=0" explicitly refuses the encoding
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			weight, err := strconv.ParseFloat(q, 64)
+			return err == nil && weight > 0
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,24 @@
+// timestamps/timestamps.go
+//
+// Package timestamps fills the created and updated fields of the records the
+// importer, the seeders and the hooks create outside of the API.
+package timestamps
+
+import (
+	"github.com/pocketbase/pocketbase/core"
+	"github.com/pocketbase/pocketbase/tools/types"
+)
+
+// Fill sets the "created" and "updated" date fields to the current time when empty.
+// schema.sql declares them as required plain date fields, so they are never filled automatically.
+func Fill(record *core.Record) {
+	for _, name := range []string{"created", "updated"} {
+		if _, ok := record.Collection().Fields.GetByName(name).(*core.DateField); !ok {
+			continue
+		}
+
+		if record.GetDateTime(name).IsZero() {
+			record.Set(name, types.NowDateTime())
+		}
+	}
+}